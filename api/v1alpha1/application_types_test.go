@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestAddToScheme(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to add types to scheme: %v", err)
+	}
+
+	for _, obj := range []runtime.Object{&Application{}, &ApplicationList{}} {
+		gvks, _, err := scheme.ObjectKinds(obj)
+		if err != nil {
+			t.Fatalf("Expected %T to be registered: %v", obj, err)
+		}
+		if gvks[0].GroupVersion() != GroupVersion {
+			t.Errorf("Expected %T in %s, got %s", obj, GroupVersion, gvks[0].GroupVersion())
+		}
+	}
+
+	if got := Resource("applications").String(); got != "applications.k8s-controller.io" {
+		t.Errorf("Expected resource applications.k8s-controller.io, got %s", got)
+	}
+}
+
+func TestApplicationDeepCopy(t *testing.T) {
+	replicas := int32(2)
+	app := &Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"tier": "frontend"}},
+		Spec: ApplicationSpec{
+			Image:    "nginx:1.27",
+			Replicas: &replicas,
+			Env:      []corev1.EnvVar{{Name: "MODE", Value: "production"}},
+		},
+		Status: ApplicationStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+
+	copied := app.DeepCopyObject().(*Application)
+	*copied.Spec.Replicas = 5
+	copied.Spec.Env[0].Value = "debug"
+	copied.Status.Conditions[0].Status = metav1.ConditionFalse
+	copied.Labels["tier"] = "backend"
+
+	if *app.Spec.Replicas != 2 || app.Spec.Env[0].Value != "production" ||
+		app.Status.Conditions[0].Status != metav1.ConditionTrue || app.Labels["tier"] != "frontend" {
+		t.Errorf("Expected the copy not to share memory with the original, got %+v", app)
+	}
+}
+
+func TestApplicationJSON(t *testing.T) {
+	app := Application{Spec: ApplicationSpec{Image: "nginx:1.27"}}
+	data, err := json.Marshal(app.Spec)
+	if err != nil {
+		t.Fatalf("Failed to marshal spec: %v", err)
+	}
+	// Optional fields are left out so that defaults of the CRD schema apply
+	if string(data) != `{"image":"nginx:1.27"}` {
+		t.Errorf("Expected only the image in the spec, got %s", data)
+	}
+}
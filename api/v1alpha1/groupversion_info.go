@@ -0,0 +1,34 @@
+// Package v1alpha1 contains the v1alpha1 API of the k8s-controller.io group
+// +kubebuilder:object:generate=true
+// +groupName=k8s-controller.io
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupVersion is the group and version of the types in this package
+	GroupVersion = schema.GroupVersion{Group: "k8s-controller.io", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the types in this package with a scheme
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme adds the types in this package to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+// Resource returns the group-qualified resource, e.g. for API errors
+func Resource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion,
+		&Application{},
+		&ApplicationList{},
+	)
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s-controller/pkg/config"
+)
+
+var configOutput string
+
+// configCmd groups the commands that inspect configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate configuration",
+	Long:  `Inspect the effective configuration and validate config files.`,
+	// Replaces the root hook, which would stop on invalid configuration
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return nil
+	},
+}
+
+// configViewCmd represents the config view command
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print the effective configuration",
+	Long: `Print the configuration merged from defaults, the config file, environment
+variables and flags, together with the source each value came from. It accepts
+the flags of serve and server so their effect can be checked. Secret values are
+redacted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		view := map[string]interface{}{
+			"config":  c.View(),
+			"sources": c.Sources(),
+		}
+		if err := writeConfigView(cmd.OutOrStdout(), configOutput, view); err != nil {
+			return err
+		}
+
+		// Viewing works on invalid configuration too; report the problems
+		// without failing, config validate is the command that checks
+		if err := c.Validate(); err != nil {
+			_, err = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+			return err
+		}
+		return nil
+	},
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a config file",
+	Long: `Validate a config file on its own, merged over the defaults but ignoring
+environment variables and flags. Unknown keys are reported as errors. Nothing
+is started and no cluster access is needed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := resolveConfigFile()
+		if path == "" {
+			return errors.New("no config file given, use --config or K8S_CONTROLLER_CONFIG")
+		}
+
+		c, err := config.LoadConfig(
+			config.WithConfigFile(path),
+			config.WithoutEnv(),
+			config.WithStrict(),
+		)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		if err := c.Validate(); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", path)
+		return err
+	},
+}
+
+// writeConfigView encodes view as YAML or JSON
+func writeConfigView(w io.Writer, format string, view map[string]interface{}) error {
+	switch format {
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(view); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(view)
+	default:
+		return fmt.Errorf("unknown output format %q, use yaml or json", format)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	configViewCmd.Flags().StringVarP(&configOutput, "output", "o", "yaml", "Output format (yaml or json)")
+	addServeFlags(configViewCmd.Flags())
+	addServerFlags(configViewCmd.Flags())
+	addLogLevelEndpointFlag(configViewCmd.Flags())
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"k8s-controller/pkg/config"
+	"k8s-controller/pkg/controller"
+	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/middleware"
+)
+
+// loggingOptions backs the request logging middleware and is swapped when
+// the config file is reloaded
+var loggingOptions = middleware.NewReloadableLoggingOptions(nil)
+
+// applyConfig applies the log level and the runtime settings of c at startup
+func applyConfig(c *config.Config) {
+	logger.SetLevel(logger.LogLevel(c.LogLevel))
+	applyRuntimeSettings(c)
+}
+
+// reloadConfig applies a reloaded configuration. The log level is only set
+// when log_level itself changed, so a level set on /debug/loglevel survives
+// reloads of other keys.
+func reloadConfig(c *config.Config, changes []config.Change) {
+	for _, change := range changes {
+		if change.Key == "log_level" {
+			logger.SetLevel(logger.LogLevel(c.LogLevel))
+		}
+	}
+	applyRuntimeSettings(c)
+}
+
+// applyRuntimeSettings applies the settings that may change while the
+// process runs besides the log level: log sampling, request logging options
+// and workqueue rate limits
+func applyRuntimeSettings(c *config.Config) {
+	if err := logger.SetSampling(logSampling(c.LogSampling)); err != nil {
+		logger.Error().Err(err).Msg("Failed to apply log sampling")
+	}
+
+	options := &middleware.LoggingOptions{
+		SkipPaths:         c.Logging.SkipPaths,
+		LogHeaders:        c.Logging.LogHeaders,
+		LogRequestBody:    c.Logging.LogRequestBody,
+		LogResponseBody:   c.Logging.LogResponseBody,
+		MaxBodyLogSize:    c.Logging.MaxBodyLogSize,
+		LogTiming:         c.Logging.LogTiming,
+		RedactHeaders:     c.Logging.RedactHeaders,
+		RedactBodyKeys:    c.Logging.RedactBodyKeys,
+		RedactQueryParams: c.Logging.RedactQueryParams,
+	}
+	// Debug mode always logs headers and request bodies
+	if c.Server.Debug {
+		options.LogHeaders = true
+		options.LogRequestBody = true
+	}
+	loggingOptions.Store(options)
+
+	controller.SetRateLimits(controller.RateLimits{
+		BaseDelay: c.RateLimit.BaseDelay,
+		MaxDelay:  c.RateLimit.MaxDelay,
+		QPS:       c.RateLimit.QPS,
+		Burst:     c.RateLimit.Burst,
+	})
+}
+
+// logSampling converts the sampling settings of the config to the logger's
+func logSampling(c config.LogSamplingConfig) logger.Sampling {
+	sampling := logger.Sampling{
+		Levels:   make(map[logger.LogLevel]logger.SamplingPolicy, len(c.Levels)),
+		Messages: make(map[string]logger.SamplingPolicy, len(c.Messages)),
+	}
+	for level, policy := range c.Levels {
+		sampling.Levels[logger.LogLevel(level)] = logger.SamplingPolicy(policy)
+	}
+	for _, m := range c.Messages {
+		sampling.Messages[m.Message] = logger.SamplingPolicy(m.Policy())
+	}
+	return sampling
+}
+
+// watchConfig reloads the config file on change until ctx is cancelled. It
+// does nothing when no config file is used.
+func watchConfig(ctx context.Context, cmd *cobra.Command) {
+	if configFile == "" {
+		return
+	}
+
+	load := func() (*config.Config, error) {
+		return loadConfig(cmd)
+	}
+	watcher := config.NewWatcher(configFile, cfg, load, reloadConfig)
+
+	go func() {
+		if err := watcher.Run(ctx); err != nil {
+			logger.Error().Err(err).Msg("Config hot reload disabled")
+		}
+	}()
+}
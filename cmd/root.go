@@ -1,51 +1,86 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"k8s-controller/pkg/config"
 	"k8s-controller/pkg/logger"
 )
 
 var (
-	logLevel   string
-	kubeconfig string
-	namespace  string
+	configFile string
 	cfg        *config.Config
+	// logFile closes the log file opened for the running command
+	logFile io.Closer
 )
 
+// flagBindings maps configuration keys to the command line flags that
+// override them. Flags missing from the running command are ignored.
+var flagBindings = map[string]string{
+	"log_level":                       "log-level",
+	"log_format":                      "log-format",
+	"log_file.path":                   "log-file",
+	"kubeconfig":                      "kubeconfig",
+	"namespace":                       "namespace",
+	"shutdown_timeout":                "shutdown-timeout",
+	"workers":                         "workers",
+	"controllers":                     "controllers",
+	"controller_workers":              "controller-workers",
+	"metrics_bind_address":            "metrics-bind-address",
+	"health_probe_bind_address":       "health-probe-bind-address",
+	"loglevel_endpoint":               "enable-loglevel-endpoint",
+	"leader_election.enabled":         "leader-elect",
+	"leader_election.lease_duration":  "leader-elect-lease-duration",
+	"leader_election.renew_deadline":  "leader-elect-renew-deadline",
+	"leader_election.retry_period":    "leader-elect-retry-period",
+	"leader_election.lease_name":      "leader-elect-lease-name",
+	"leader_election.lease_namespace": "leader-elect-lease-namespace",
+	"server.port":                     "port",
+	"server.debug":                    "debug",
+}
+
+// skipConfigValidation annotates commands that run whatever the
+// configuration, since they don't use it
+const skipConfigValidation = "skip-config-validation"
+
 var rootCmd = &cobra.Command{
 	Use:   "k8s-controller",
 	Short: "A Kubernetes controller",
 	Long: `A Kubernetes controller application that manages custom resources
 and performs operations based on Kubernetes events.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// Load configuration
+		// Flags parsed fine, configuration errors don't need the usage text
+		cmd.SilenceUsage = true
+
+		// Load configuration: flag > env > config file > default
 		var err error
-		cfg, err = config.LoadConfig()
+		cfg, err = loadConfig(cmd)
 		if err != nil {
 			return err
 		}
 
-		// Override with command line flags if provided
-		if cmd.Flags().Changed("log-level") {
-			cfg.LogLevel = logLevel
-		}
-		if cmd.Flags().Changed("kubeconfig") {
-			cfg.KubeConfig = kubeconfig
-		}
-		if cmd.Flags().Changed("namespace") {
-			cfg.Namespace = namespace
+		// Fail fast with every invalid field before anything starts
+		if validatesConfig(cmd) {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
 		}
 
-		// Initialize logger
-		logger.Init(logger.LogLevel(cfg.LogLevel))
+		// Initialize logger and the settings that may be reloaded later
+		logFile, err = logger.InitWithOptions(loggerOptions(cfg))
+		if err != nil {
+			return err
+		}
+		applyConfig(cfg)
 		logger.Debug().Msg("Debug logging enabled")
-		logger.Debug().Interface("config", cfg).Msg("Configuration loaded")
+		logger.Debug().Interface("config", cfg.View()).Msg("Configuration loaded")
 
 		return nil
 	},
@@ -55,10 +90,71 @@ and performs operations based on Kubernetes events.`,
 	},
 }
 
+// validatesConfig reports whether cmd needs a valid configuration. Commands
+// annotated with skipConfigValidation and cobra's help command don't.
+func validatesConfig(cmd *cobra.Command) bool {
+	return cmd.Annotations[skipConfigValidation] == "" && cmd.Name() != "help"
+}
+
+// resolveConfigFile falls back to $K8S_CONTROLLER_CONFIG when --config is
+// not set and returns the config file path
+func resolveConfigFile() string {
+	if configFile == "" {
+		configFile = os.Getenv(config.EnvPrefix + "_CONFIG")
+	}
+	return configFile
+}
+
+// loadConfig merges the defaults, the config file, the environment and the
+// flags of cmd
+func loadConfig(cmd *cobra.Command, opts ...config.Option) (*config.Config, error) {
+	opts = append([]config.Option{
+		config.WithConfigFile(resolveConfigFile()),
+		config.WithFlags(cmd.Flags(), flagBindings),
+	}, opts...)
+	return config.LoadConfig(opts...)
+}
+
+// loggerOptions builds the logger settings of c, including the optional
+// rotating log file
+func loggerOptions(c *config.Config) logger.Options {
+	opts := logger.Options{
+		Level:  logger.LogLevel(c.LogLevel),
+		Format: logger.LogFormat(c.LogFormat),
+	}
+	if c.LogFile.Path != "" {
+		opts.File = &logger.FileOptions{
+			Path:           c.LogFile.Path,
+			Format:         logger.LogFormat(c.LogFile.Format),
+			MaxSizeMB:      c.LogFile.MaxSizeMB,
+			MaxAgeDays:     c.LogFile.MaxAgeDays,
+			MaxBackups:     c.LogFile.MaxBackups,
+			Compress:       c.LogFile.Compress,
+			RotateInterval: c.LogFile.RotateInterval,
+		}
+		opts.DisableStdout = !c.LogFile.Stdout
+	}
+	return opts
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The command context is cancelled on SIGINT or SIGTERM.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	// Restore default signal handling after the first signal so a second
+	// one terminates the process immediately
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if logFile != nil {
+		_ = logFile.Close()
+	}
 	if err != nil {
 		os.Exit(1)
 	}
@@ -68,27 +164,11 @@ func init() {
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
-	rootCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "Path to kubeconfig file")
-	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace to operate in")
-	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (trace, debug, info, warn, error)")
-
-	// Bind flags to environment variables
-	// Use K8S_CONTROLLER_KUBECONFIG instead of --kubeconfig
-	if err := viper.BindPFlag("kubeconfig", rootCmd.PersistentFlags().Lookup("kubeconfig")); err != nil {
-		fmt.Fprintf(os.Stderr, "Error binding kubeconfig flag: %v\n", err)
-		os.Exit(1)
-	}
-	if err := viper.BindPFlag("namespace", rootCmd.PersistentFlags().Lookup("namespace")); err != nil {
-		fmt.Fprintf(os.Stderr, "Error binding namespace flag: %v\n", err)
-		os.Exit(1)
-	}
-	if err := viper.BindPFlag("log_level", rootCmd.PersistentFlags().Lookup("log-level")); err != nil {
-		fmt.Fprintf(os.Stderr, "Error binding log-level flag: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Configure Viper
-	viper.SetEnvPrefix("K8S_CONTROLLER")
-	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
-	viper.AutomaticEnv()
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to a YAML, TOML or JSON config file (env K8S_CONTROLLER_CONFIG)")
+	rootCmd.PersistentFlags().StringP("kubeconfig", "k", "", "Path to kubeconfig file")
+	rootCmd.PersistentFlags().StringP("namespace", "n", "", "Kubernetes namespace to operate in")
+	rootCmd.PersistentFlags().StringP("log-level", "l", "info", "Log level (trace, debug, info, warn, error)")
+	rootCmd.PersistentFlags().String("log-format", "", "Log format (console, json, logfmt); defaults to json when stdout is not a terminal")
+	rootCmd.PersistentFlags().String("log-file", "", "Also write logs to this file, rotated by size and age")
+	rootCmd.PersistentFlags().Duration("shutdown-timeout", 30*time.Second, "Time allowed for in-flight work to drain after SIGTERM/SIGINT")
 }
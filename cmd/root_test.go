@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"k8s-controller/pkg/logger"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidationPerCommand(t *testing.T) {
+	t.Setenv("K8S_CONTROLLER_SERVER_PORT", "99999")
+	defer logger.SetOutput(new(bytes.Buffer))
+
+	testCases := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: []string{"version"}},
+		{args: []string{"help"}},
+		{args: []string{"config", "view"}},
+		{args: []string{"server"}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd.SetArgs(tc.args)
+			rootCmd.SetOut(&out)
+			rootCmd.SetErr(&out)
+			defer rootCmd.SetArgs(nil)
+
+			err := rootCmd.Execute()
+			if tc.wantErr && (err == nil || !strings.Contains(err.Error(), "server.port")) {
+				t.Errorf("Expected a validation error for server.port, got %v", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Expected the command to run with an invalid config, got %v", err)
+			}
+		})
+	}
+}
@@ -1,10 +1,40 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/valyala/fasthttp"
+	"k8s-controller/pkg/controller"
+	"k8s-controller/pkg/healthz"
+	"k8s-controller/pkg/leaderelection"
 	"k8s-controller/pkg/logger"
+	"k8s.io/client-go/informers"
 )
 
+// resyncPeriod is how often informers replay their cache to the handlers
+const resyncPeriod = 30 * time.Second
+
+// stuckReconcileThreshold is how long a single reconcile may run before the
+// workqueue liveness check fails
+const stuckReconcileThreshold = 10 * time.Minute
+
+// leaderElectionHealthTolerance is how long past lease expiry the leader may
+// go without renewing before the leader election liveness check fails
+const leaderElectionHealthTolerance = 20 * time.Second
+
+// inClusterNamespaceFile holds the pod namespace when running in a cluster
+const inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// leaderElector is set while serve runs with leader election enabled and
+// backs the /leader endpoint
+var leaderElector *leaderelection.Elector
+
 // serveCmd represents the serve command
 var serveCmd = &cobra.Command{
 	Use:   "serve",
@@ -12,39 +42,183 @@ var serveCmd = &cobra.Command{
 	Long:  `Start the Kubernetes controller to watch and manage resources.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		logger.Info().Msg("Starting Kubernetes controller...")
-		
-		// Get command line flags
-		kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
-		namespace, _ := cmd.Flags().GetString("namespace")
-		leaderElect, _ := cmd.Flags().GetBool("leader-elect")
-		workers, _ := cmd.Flags().GetInt("workers")
-		
+
+		le := &cfg.LeaderElection
+
 		logger.Info().
-			Str("kubeconfig", kubeconfig).
-			Str("namespace", namespace).
-			Bool("leader-elect", leaderElect).
-			Int("workers", workers).
+			Str("kubeconfig", cfg.KubeConfig).
+			Str("namespace", cfg.Namespace).
+			Bool("leader-elect", le.Enabled).
+			Int("workers", cfg.Workers).
+			Strs("controllers", cfg.Controllers).
+			Interface("controller-workers", cfg.ControllerWorkers).
+			Str("metrics-bind-address", cfg.MetricsBindAddress).
+			Str("health-probe-bind-address", cfg.HealthProbeBindAddress).
 			Msg("Controller configuration")
-		
-		// Example logging at different levels
-		logger.Trace().Msg("This is a trace message")
-		logger.Debug().Msg("This is a debug message")
-		logger.Info().Msg("This is an info message")
-		logger.Warn().Msg("This is a warning message")
-		logger.Error().Msg("This is an error message")
-		
-		// TODO: Implement controller logic
-		logger.Info().Msg("Controller is running. Press Ctrl+C to stop.")
-		
-		// Block indefinitely
-		select {}
+
+		// Build the Kubernetes client
+		clientset, err := controller.NewClientset(cfg.KubeConfig)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to create Kubernetes client")
+		}
+
+		// Shared informers scoped to the configured namespace (all namespaces if empty)
+		deps := controller.Dependencies{
+			Client: clientset,
+			Informers: informers.NewSharedInformerFactoryWithOptions(
+				clientset,
+				resyncPeriod,
+				informers.WithNamespace(cfg.Namespace),
+			),
+			Events: controller.NewEventRecorder(clientset),
+		}
+		defer deps.Events.Shutdown()
+
+		opts := controller.Options{
+			Enabled:           cfg.Controllers,
+			Workers:           cfg.Workers,
+			ControllerWorkers: cfg.ControllerWorkers,
+		}
+
+		// Liveness and readiness checks wired to controller state
+		if err := readinessChecks.Add(
+			healthz.NamedCheck("informer-sync", controller.DefaultRegistry.CheckInformersSynced),
+			healthz.KubeAPICheck(clientset),
+		); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to register readiness checks")
+		}
+		if err := livenessChecks.Add(
+			healthz.NamedCheck("workqueue", controller.DefaultRegistry.CheckWorkqueues(stuckReconcileThreshold)),
+		); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to register liveness checks")
+		}
+
+		run := func(ctx context.Context) {
+			logger.Info().Msg("Controller is running. Press Ctrl+C to stop.")
+			if err := controller.DefaultRegistry.Start(ctx, deps, opts); err != nil {
+				logger.Fatal().Err(err).Msg("Controller stopped with error")
+			}
+		}
+
+		start := run
+		if le.Enabled {
+			leaderElector, err = leaderelection.New(clientset, leaderelection.Options{
+				LeaseName:      le.LeaseName,
+				LeaseNamespace: leaseNamespace(),
+				LeaseDuration:  le.LeaseDuration,
+				RenewDeadline:  le.RenewDeadline,
+				RetryPeriod:    le.RetryPeriod,
+			}, run)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to set up leader election")
+			}
+			if err := livenessChecks.Add(healthz.NamedCheck("leader-election", func(ctx context.Context) error {
+				return leaderElector.Check(leaderElectionHealthTolerance)
+			})); err != nil {
+				logger.Fatal().Err(err).Msg("Failed to register leader election check")
+			}
+
+			start = func(ctx context.Context) {
+				// Run only returns early when leadership is lost
+				leaderElector.Run(ctx)
+				if ctx.Err() == nil {
+					logger.Fatal().Msg("Leader election lost")
+				}
+			}
+		}
+
+		watchConfig(cmd.Context(), cmd)
+		defer setupTracing(cmd.Context())()
+
+		if err := runUntilShutdown(cmd.Context(), cfg.ShutdownTimeout, withOpsServer(start)); err != nil {
+			logger.Fatal().Err(err).Msg("Controller did not shut down cleanly")
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
-	
-	// Add serve-specific flags
-	serveCmd.Flags().Bool("leader-elect", false, "Enable leader election")
-	serveCmd.Flags().Int("workers", 2, "Number of worker threads")
-}
\ No newline at end of file
+	addServeFlags(serveCmd.Flags())
+	addLogLevelEndpointFlag(serveCmd.Flags())
+}
+
+// addServeFlags defines the serve-specific flags on fs
+func addServeFlags(fs *pflag.FlagSet) {
+	fs.Int("workers", 2, "Number of worker threads")
+	fs.StringSlice("controllers", []string{"*"}, "Controllers to run; '*' enables all, '-name' disables one")
+	fs.StringToInt("controller-workers", nil, "Per-controller worker counts overriding --workers (e.g. deployment=4)")
+	fs.String("metrics-bind-address", ":8080", "Address the metrics endpoint binds to; '0' disables it")
+	fs.String("health-probe-bind-address", ":8081", "Address the health probe endpoint binds to; '0' disables it")
+
+	// Leader election flags
+	fs.Bool("leader-elect", false, "Enable leader election")
+	fs.Duration("leader-elect-lease-duration", 15*time.Second, "Duration non-leaders wait before trying to acquire the lease")
+	fs.Duration("leader-elect-renew-deadline", 10*time.Second, "Duration the leader retries refreshing the lease before giving up")
+	fs.Duration("leader-elect-retry-period", 2*time.Second, "Duration between leader election attempts")
+	fs.String("leader-elect-lease-name", "k8s-controller", "Name of the Lease object used for leader election")
+	fs.String("leader-elect-lease-namespace", "", "Namespace of the Lease object (defaults to --namespace, then the pod namespace)")
+}
+
+// leaseNamespace resolves the namespace of the leader election Lease
+func leaseNamespace() string {
+	if cfg.LeaderElection.LeaseNamespace != "" {
+		return cfg.LeaderElection.LeaseNamespace
+	}
+	if cfg.Namespace != "" {
+		return cfg.Namespace
+	}
+	if data, err := os.ReadFile(inClusterNamespaceFile); err == nil {
+		if ns := strings.TrimSpace(string(data)); ns != "" {
+			return ns
+		}
+	}
+	return "default"
+}
+
+// withOpsServer wraps the controller start function so the ops HTTP server
+// runs for the lifetime of the controller: /metrics on the metrics address
+// and the health probes on the health probe address. Both share one listener
+// when the addresses are equal.
+func withOpsServer(start func(ctx context.Context)) func(ctx context.Context) {
+	metricsAddr := opsAddress(cfg.MetricsBindAddress)
+	probeAddr := opsAddress(cfg.HealthProbeBindAddress)
+
+	handlers := make(map[string]fasthttp.RequestHandler)
+	if metricsAddr != "" && metricsAddr == probeAddr {
+		handlers[metricsAddr] = newOpsHandler(true, true)
+	} else {
+		if metricsAddr != "" {
+			handlers[metricsAddr] = newOpsHandler(true, false)
+		}
+		if probeAddr != "" {
+			handlers[probeAddr] = newOpsHandler(false, true)
+		}
+	}
+	if len(handlers) == 0 {
+		return start
+	}
+
+	return func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for addr, handler := range handlers {
+			wg.Add(1)
+			go func(addr string, handler fasthttp.RequestHandler) {
+				defer wg.Done()
+				if err := serveHTTP(ctx, addr, handler, cfg.ShutdownTimeout); err != nil {
+					logger.Fatal().Err(err).Str("address", addr).Msg("Ops HTTP server failed")
+				}
+			}(addr, handler)
+		}
+
+		start(ctx)
+		wg.Wait()
+	}
+}
+
+// opsAddress returns addr, or "" when the endpoint is disabled
+func opsAddress(addr string) string {
+	if addr == "0" {
+		return ""
+	}
+	return addr
+}
@@ -1,18 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 	"github.com/valyala/fasthttp"
+	"k8s-controller/pkg/healthz"
 	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/metrics"
 	"k8s-controller/pkg/middleware"
-	"os"
+	"k8s-controller/pkg/router"
+	"time"
 )
 
 var (
-	serverPort int
-	debugMode bool
+	// livenessChecks and readinessChecks back /healthz and /readyz; serve
+	// installs controller checks on top of the default ping check
+	livenessChecks  = healthz.NewRegistry("healthz")
+	readinessChecks = healthz.NewRegistry("readyz")
 )
 
 // serverCmd represents the server command
@@ -23,74 +30,144 @@ var serverCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		logger.Info().Msg("Starting HTTP server...")
 
-		// Get server port from flag or environment variable
-		port := serverPort
+		port := cfg.Server.Port
 		logger.Info().Int("port", port).Msg("Server configuration")
 
-		// Create base handler
-		baseHandler := func(ctx *fasthttp.RequestCtx) {
-			path := string(ctx.Path())
-
-			switch path {
-			case "/":
-				ctx.SetContentType("text/plain")
-				_, err := fmt.Fprintf(ctx, "Welcome to the k8s-controller HTTP server!")
-				if err != nil {
-					logger.Error().Err(err).Msg("Failed to write response")
-				}
-			case "/health":
-				ctx.SetContentType("application/json")
-				_, err := fmt.Fprintf(ctx, `{"status":"healthy"}`)
-				if err != nil {
-					logger.Error().Err(err).Msg("Failed to write health response")
-				}
-			default:
-				ctx.SetStatusCode(404)
-				_, err := fmt.Fprintf(ctx, "Not found")
-				if err != nil {
-					logger.Error().Err(err).Msg("Failed to write not found response")
-				}
-			}
-		}
-		
-		// Set up logging options
-		loggingOptions := middleware.DefaultLoggingOptions()
-		// Enable header logging for development
-		if debugMode {
-			loggingOptions.LogHeaders = true
-			loggingOptions.LogRequestBody = true
+		if cfg.Server.Debug {
 			logger.Info().Msg("Debug mode enabled: detailed request logging activated")
 		}
-		
-		// Wrap base handler with request logging middleware
-		handler := middleware.EnhancedRequestLogger(loggingOptions)(baseHandler)
+		watchConfig(cmd.Context(), cmd)
+		defer setupTracing(cmd.Context())()
+
+		handler := newHTTPHandler()
 
 		// Start server
 		addr := fmt.Sprintf(":%d", port)
-		logger.Info().Str("address", addr).Msg("HTTP server is running")
-
-		if err := fasthttp.ListenAndServe(addr, handler); err != nil {
-			logger.Fatal().Err(err).Msg("Failed to start HTTP server")
+		if err := serveHTTP(cmd.Context(), addr, handler, cfg.ShutdownTimeout); err != nil {
+			logger.Fatal().Err(err).Msg("HTTP server failed")
 		}
 	},
 }
 
-func init() {
-	rootCmd.AddCommand(serverCmd)
+// newHTTPHandler builds every HTTP route of the server command wrapped with
+// the request middleware
+func newHTTPHandler() fasthttp.RequestHandler {
+	r := router.New()
+	r.GET("/", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("text/plain")
+		_, err := fmt.Fprintf(ctx, "Welcome to the k8s-controller HTTP server!")
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Failed to write response")
+		}
+	})
+	addProbeRoutes(r)
+	r.GET("/metrics", metrics.Handler())
+	addLogLevelRoutes(r)
+
+	return withRequestMiddleware(r)
+}
+
+// newOpsHandler builds the routes serve exposes on one ops address: /metrics
+// on the metrics address and the probes on the health probe address, so
+// neither port serves anything else. The log level endpoint is added to the
+// probes when enabled.
+func newOpsHandler(metricsRoutes, probeRoutes bool) fasthttp.RequestHandler {
+	r := router.New()
+	if metricsRoutes {
+		r.GET("/metrics", metrics.Handler())
+	}
+	if probeRoutes {
+		addProbeRoutes(r)
+		addLogLevelRoutes(r)
+	}
+	return withRequestMiddleware(r)
+}
+
+// addLogLevelRoutes adds /debug/loglevel when enabled. Anyone who reaches it
+// can change log levels, so it is opt-in.
+func addLogLevelRoutes(r *router.Router) {
+	if !cfg.LogLevelEndpoint {
+		return
+	}
+	logLevelHandler := logger.LevelHandler()
+	r.GET("/debug/loglevel", logLevelHandler)
+	r.PUT("/debug/loglevel", logLevelHandler)
+}
+
+// addProbeRoutes adds the liveness and readiness endpoints, each check below
+// its endpoint, and the read-only leadership status
+func addProbeRoutes(r *router.Router) {
+	livenessHandler := livenessChecks.Handler()
+	readinessHandler := readinessChecks.Handler()
 
-	// Add server-specific flags
-	serverCmd.Flags().IntVar(&serverPort, "port", 8080, "HTTP server port")
-	serverCmd.Flags().BoolVar(&debugMode, "debug", false, "Enable debug mode with detailed request logging")
+	r.GET(livenessChecks.Path(), livenessHandler)
+	r.GET(livenessChecks.Path()+"/*check", livenessHandler)
+	// /health predates /healthz and is kept for existing probes and monitors
+	r.GET("/health", livenessChecks.AggregateHandler())
+	r.GET(readinessChecks.Path(), readinessHandler)
+	r.GET(readinessChecks.Path()+"/*check", readinessHandler)
+	r.GET("/leader", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		if err := json.NewEncoder(ctx).Encode(leaderElector.Status()); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Failed to write leader response")
+		}
+	})
+}
+
+// withRequestMiddleware wraps the router with tracing, request ID, metrics
+// and request logging middleware
+func withRequestMiddleware(r *router.Router) fasthttp.RequestHandler {
+	return middleware.Chain(
+		middleware.Tracing,
+		middleware.RequestID,
+		middleware.RequestMetrics,
+		middleware.ReloadableRequestLogger(loggingOptions),
+	)(r.Handler())
+}
+
+// serveHTTP runs a fasthttp server on addr until ctx is cancelled, then stops
+// accepting connections and waits up to timeout for in-flight requests.
+func serveHTTP(ctx context.Context, addr string, handler fasthttp.RequestHandler, timeout time.Duration) error {
+	server := &fasthttp.Server{Handler: handler}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe(addr)
+	}()
+	logger.Info().Str("address", addr).Msg("HTTP server is running")
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
 
-	// Bind flag to environment variable
-	if err := viper.BindPFlag("server_port", serverCmd.Flags().Lookup("port")); err != nil {
-		fmt.Fprintf(os.Stderr, "Error binding server_port flag: %v\n", err)
-		os.Exit(1)
+	// Stop accepting connections and wait for in-flight requests
+	logger.Info().Str("address", addr).Dur("timeout", timeout).Msg("Draining HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.ShutdownWithContext(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down HTTP server on %s: %w", addr, err)
 	}
+	logger.Info().Str("address", addr).Msg("HTTP server stopped")
 
-	// Set environment variable name
-	viper.SetDefault("server_port", 8080)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(serverCmd)
+	addServerFlags(serverCmd.Flags())
+	addLogLevelEndpointFlag(serverCmd.Flags())
+}
+
+// addServerFlags defines the server-specific flags on fs
+func addServerFlags(fs *pflag.FlagSet) {
+	fs.Int("port", 8080, "HTTP server port")
+	fs.Bool("debug", false, "Enable debug mode with detailed request logging")
+}
 
-	// Set up automatic binding to SERVER_PORT env var
-	viper.AutomaticEnv()
-}
\ No newline at end of file
+// addLogLevelEndpointFlag defines the flag shared by serve and server that
+// enables /debug/loglevel
+func addLogLevelEndpointFlag(fs *pflag.FlagSet) {
+	fs.Bool("enable-loglevel-endpoint", false, "Serve the unauthenticated /debug/loglevel endpoint (serve uses the health probe address)")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/valyala/fasthttp"
+	"k8s-controller/pkg/config"
+	"k8s-controller/pkg/logger"
+	"testing"
+)
+
+// get serves a GET request for path with handler and returns the response
+func get(handler fasthttp.RequestHandler, path string) *fasthttp.Response {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(path)
+	handler(ctx)
+	return &ctx.Response
+}
+
+func TestHTTPHandlerHealthAlias(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+	defer func(previous *config.Config) { cfg = previous }(cfg)
+	cfg = &config.Config{}
+
+	handler := newHTTPHandler()
+	for _, path := range []string{"/health", "/healthz"} {
+		response := get(handler, path)
+		if response.StatusCode() != fasthttp.StatusOK || string(response.Body()) != "ok" {
+			t.Errorf("Expected %s to report ok, got %d %q", path, response.StatusCode(), response.Body())
+		}
+	}
+}
+
+func TestHTTPHandlerLogLevelEndpoint(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+	defer func(previous *config.Config) { cfg = previous }(cfg)
+
+	testCases := []struct {
+		name    string
+		enabled bool
+		status  int
+	}{
+		{name: "disabled by default", status: fasthttp.StatusNotFound},
+		{name: "enabled", enabled: true, status: fasthttp.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg = &config.Config{LogLevelEndpoint: tc.enabled}
+			for name, handler := range map[string]fasthttp.RequestHandler{
+				"server":       newHTTPHandler(),
+				"probe":        newOpsHandler(false, true),
+				"metrics only": newOpsHandler(true, false),
+			} {
+				status := tc.status
+				if name == "metrics only" {
+					status = fasthttp.StatusNotFound
+				}
+				if got := get(handler, "/debug/loglevel").StatusCode(); got != status {
+					t.Errorf("Expected /debug/loglevel on the %s handler to return %d, got %d", name, status, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReloadConfigKeepsRuntimeLogLevel(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+	defer logger.SetLevel(logger.InfoLevel)
+
+	reloaded := &config.Config{LogLevel: "info"}
+
+	// A level set on /debug/loglevel survives a reload of other keys
+	logger.SetLevel(logger.TraceLevel)
+	reloadConfig(reloaded, []config.Change{{Key: "rate_limit.qps", Old: 10.0, New: 20.0}})
+	if level := logger.Levels().Level; level != logger.TraceLevel {
+		t.Errorf("Expected the runtime level to be kept, got %s", level)
+	}
+
+	reloadConfig(reloaded, []config.Change{{Key: "log_level", Old: "debug", New: "info"}})
+	if level := logger.Levels().Level; level != logger.InfoLevel {
+		t.Errorf("Expected a changed log_level to be applied, got %s", level)
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s-controller/pkg/logger"
+)
+
+// runUntilShutdown runs fn until it returns on its own or ctx is cancelled.
+// After cancellation fn has up to timeout to return before an error is
+// reported, so in-flight work can drain without blocking shutdown forever.
+func runUntilShutdown(ctx context.Context, timeout time.Duration, fn func(ctx context.Context)) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	logger.Info().Dur("timeout", timeout).Msg("Shutdown signal received, draining in-flight work")
+
+	select {
+	case <-done:
+		logger.Info().Msg("Shutdown complete")
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s waiting for in-flight work to drain", timeout)
+	}
+}
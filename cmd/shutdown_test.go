@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/valyala/fasthttp"
+	"io"
+	"k8s-controller/pkg/config"
+	"k8s-controller/pkg/logger"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunUntilShutdown(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	testCases := []struct {
+		name    string
+		cancel  bool
+		timeout time.Duration
+		fn      func(ctx context.Context)
+		wantErr bool
+	}{
+		{
+			name:    "returns on its own",
+			timeout: time.Second,
+			fn:      func(ctx context.Context) {},
+		},
+		{
+			name:    "drains before the timeout",
+			cancel:  true,
+			timeout: 5 * time.Second,
+			fn: func(ctx context.Context) {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+			},
+		},
+		{
+			name:    "drain times out",
+			cancel:  true,
+			timeout: 50 * time.Millisecond,
+			fn: func(ctx context.Context) {
+				<-ctx.Done()
+				<-release
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+
+			err := runUntilShutdown(ctx, tc.timeout, tc.fn)
+			if tc.wantErr && (err == nil || !strings.Contains(err.Error(), "timed out")) {
+				t.Errorf("Expected a timeout error, got %v", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithOpsServer(t *testing.T) {
+	// Both servers log concurrently
+	logger.SetOutput(io.Discard)
+	defer func(previous *config.Config) { cfg = previous }(cfg)
+
+	metricsAddr, probeAddr := freeAddress(t), freeAddress(t)
+	cfg = &config.Config{
+		MetricsBindAddress:     metricsAddr,
+		HealthProbeBindAddress: probeAddr,
+		ShutdownTimeout:        time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		withOpsServer(func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+		})(ctx)
+	}()
+	<-started
+
+	// Each address serves only its own endpoints while the controller runs
+	testCases := []struct {
+		addr   string
+		path   string
+		status int
+	}{
+		{metricsAddr, "/metrics", fasthttp.StatusOK},
+		{metricsAddr, "/healthz", fasthttp.StatusNotFound},
+		{metricsAddr, "/debug/loglevel", fasthttp.StatusNotFound},
+		{probeAddr, "/healthz", fasthttp.StatusOK},
+		{probeAddr, "/readyz", fasthttp.StatusOK},
+		{probeAddr, "/metrics", fasthttp.StatusNotFound},
+		{probeAddr, "/", fasthttp.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		if status := waitForStatus(t, tc.addr, tc.path); status != tc.status {
+			t.Errorf("Expected %s on the %s address to return %d, got %d", tc.path, tc.addr, tc.status, status)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the ops server to stop")
+	}
+	for _, addr := range []string{metricsAddr, probeAddr} {
+		if _, _, err := fasthttp.Get(nil, "http://"+addr+"/healthz"); err == nil {
+			t.Errorf("Expected the ops server on %s to stop with the controller", addr)
+		}
+	}
+}
+
+func TestWithOpsServerDisabled(t *testing.T) {
+	defer func(previous *config.Config) { cfg = previous }(cfg)
+	cfg = &config.Config{MetricsBindAddress: "0", HealthProbeBindAddress: ""}
+
+	called := false
+	withOpsServer(func(ctx context.Context) { called = true })(context.Background())
+	if !called {
+		t.Error("Expected the start function to run without an ops server")
+	}
+}
+
+// freeAddress returns a local address nothing listens on
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve a port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// waitForStatus requests path on addr until the server accepts connections
+// and returns the response status
+func waitForStatus(t *testing.T, addr, path string) int {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, _, err := fasthttp.Get(nil, "http://"+addr+path)
+		if err == nil {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the ops server on %s: %v", addr, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
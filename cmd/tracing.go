@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+
+	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/tracing"
+)
+
+// setupTracing installs the OTLP trace exporter when tracing is enabled and
+// returns a function that flushes pending spans on shutdown
+func setupTracing(ctx context.Context) func() {
+	tc := cfg.Tracing
+	if !tc.Enabled {
+		return func() {}
+	}
+
+	shutdown, err := tracing.Setup(ctx, tracing.Options{
+		Endpoint:    tc.Endpoint,
+		Protocol:    tc.Protocol,
+		Insecure:    tc.Insecure,
+		Headers:     tc.Headers,
+		ServiceName: tc.ServiceName,
+		SampleRatio: tc.SampleRatio,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+	logger.Info().
+		Str("endpoint", tc.Endpoint).
+		Str("protocol", tc.Protocol).
+		Float64("sample_ratio", tc.SampleRatio).
+		Msg("Tracing enabled")
+
+	return func() {
+		// The command context is already cancelled, flushing gets its own deadline
+		flushCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := shutdown(flushCtx); err != nil {
+			logger.Error().Err(err).Msg("Failed to flush traces")
+		}
+	}
+}
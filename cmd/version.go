@@ -16,9 +16,10 @@ var (
 
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print the version information",
-	Long:  `Print the version, commit, and build date information for the k8s-controller.`,
+	Use:         "version",
+	Short:       "Print the version information",
+	Long:        `Print the version, commit, and build date information for the k8s-controller.`,
+	Annotations: map[string]string{skipConfigValidation: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Log structured version info
 		logger.Info().
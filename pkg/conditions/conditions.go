@@ -0,0 +1,210 @@
+package conditions
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types shared by the custom resources of the controller
+const (
+	// Ready summarizes the other conditions, see SetSummary
+	Ready = "Ready"
+	// Progressing is True while the resource moves towards its desired state
+	Progressing = "Progressing"
+	// Degraded is True when the resource failed to reach or keep its desired
+	// state
+	Degraded = "Degraded"
+)
+
+// Reasons set by SetSummary
+const (
+	// ReasonReady is the reason of a True Ready condition
+	ReasonReady = "Ready"
+	// ReasonNotObserved is the reason of an Unknown Ready condition when a
+	// condition it depends on has not been set yet
+	ReasonNotObserved = "NotObserved"
+)
+
+// Object is a resource whose status carries conditions
+type Object interface {
+	GetGeneration() int64
+	GetConditions() []metav1.Condition
+	SetConditions(conditions []metav1.Condition)
+}
+
+// Get returns a copy of the condition of conditionType, or nil when it is
+// not set
+func Get(obj Object, conditionType string) *metav1.Condition {
+	for _, condition := range obj.GetConditions() {
+		if condition.Type == conditionType {
+			return &condition
+		}
+	}
+	return nil
+}
+
+// IsTrue reports whether the condition of conditionType is True
+func IsTrue(obj Object, conditionType string) bool {
+	return hasStatus(obj, conditionType, metav1.ConditionTrue)
+}
+
+// IsFalse reports whether the condition of conditionType is False
+func IsFalse(obj Object, conditionType string) bool {
+	return hasStatus(obj, conditionType, metav1.ConditionFalse)
+}
+
+// IsUnknown reports whether the condition of conditionType is Unknown or
+// not set
+func IsUnknown(obj Object, conditionType string) bool {
+	condition := Get(obj, conditionType)
+	return condition == nil || condition.Status == metav1.ConditionUnknown
+}
+
+// IsCurrent reports whether the condition of conditionType was set for the
+// current generation of obj, rather than for an older spec
+func IsCurrent(obj Object, conditionType string) bool {
+	condition := Get(obj, conditionType)
+	return condition != nil && condition.ObservedGeneration == obj.GetGeneration()
+}
+
+func hasStatus(obj Object, conditionType string, status metav1.ConditionStatus) bool {
+	condition := Get(obj, conditionType)
+	return condition != nil && condition.Status == status
+}
+
+// Equal reports whether two conditions have the same type, status, reason,
+// message and observed generation. Transition times are ignored.
+func Equal(a, b metav1.Condition) bool {
+	return a.Type == b.Type &&
+		a.Status == b.Status &&
+		a.Reason == b.Reason &&
+		a.Message == b.Message &&
+		a.ObservedGeneration == b.ObservedGeneration
+}
+
+// Set adds or replaces the condition of condition.Type on obj and reports
+// whether anything changed. The observed generation is set to the
+// generation of obj. The transition time is kept while the status stays the
+// same and set to now when it changes, unless condition carries one. obj is
+// modified in place, so objects taken from a lister must be passed as a
+// DeepCopy() to keep the informer's cache intact.
+func Set(obj Object, condition metav1.Condition) bool {
+	condition.ObservedGeneration = obj.GetGeneration()
+
+	conditions := append([]metav1.Condition(nil), obj.GetConditions()...)
+	for i, existing := range conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if Equal(existing, condition) {
+			return false
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		} else if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		conditions[i] = condition
+		obj.SetConditions(conditions)
+		return true
+	}
+
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	obj.SetConditions(append(conditions, condition))
+	return true
+}
+
+// MarkTrue sets the condition of conditionType to True and reports whether
+// anything changed
+func MarkTrue(obj Object, conditionType, reason, messageFormat string, args ...interface{}) bool {
+	return mark(obj, conditionType, metav1.ConditionTrue, reason, messageFormat, args...)
+}
+
+// MarkFalse sets the condition of conditionType to False and reports whether
+// anything changed
+func MarkFalse(obj Object, conditionType, reason, messageFormat string, args ...interface{}) bool {
+	return mark(obj, conditionType, metav1.ConditionFalse, reason, messageFormat, args...)
+}
+
+// MarkUnknown sets the condition of conditionType to Unknown and reports
+// whether anything changed
+func MarkUnknown(obj Object, conditionType, reason, messageFormat string, args ...interface{}) bool {
+	return mark(obj, conditionType, metav1.ConditionUnknown, reason, messageFormat, args...)
+}
+
+func mark(obj Object, conditionType string, status metav1.ConditionStatus, reason, messageFormat string, args ...interface{}) bool {
+	return Set(obj, metav1.Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: fmt.Sprintf(messageFormat, args...),
+	})
+}
+
+// Delete removes the condition of conditionType and reports whether it was
+// set
+func Delete(obj Object, conditionType string) bool {
+	conditions := obj.GetConditions()
+	kept := make([]metav1.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		if condition.Type != conditionType {
+			kept = append(kept, condition)
+		}
+	}
+	if len(kept) == len(conditions) {
+		return false
+	}
+	obj.SetConditions(kept)
+	return true
+}
+
+// SetSummary sets the Ready condition from Degraded, Progressing and the
+// conditions of dependsOn, and reports whether it changed. Ready is
+//   - False with the reason and message of Degraded when it is True,
+//   - else False with those of the first condition of dependsOn that is False,
+//   - else False with those of Progressing when it is True,
+//   - else Unknown when a condition of dependsOn is Unknown or not set,
+//   - else True with ReasonReady.
+func SetSummary(obj Object, dependsOn ...string) bool {
+	summary := metav1.Condition{Type: Ready, Status: metav1.ConditionTrue, Reason: ReasonReady}
+
+	if degraded := Get(obj, Degraded); degraded != nil && degraded.Status == metav1.ConditionTrue {
+		return Set(obj, summaryOf(metav1.ConditionFalse, degraded))
+	}
+	for _, conditionType := range dependsOn {
+		if condition := Get(obj, conditionType); condition != nil && condition.Status == metav1.ConditionFalse {
+			return Set(obj, summaryOf(metav1.ConditionFalse, condition))
+		}
+	}
+	if progressing := Get(obj, Progressing); progressing != nil && progressing.Status == metav1.ConditionTrue {
+		return Set(obj, summaryOf(metav1.ConditionFalse, progressing))
+	}
+	for _, conditionType := range dependsOn {
+		condition := Get(obj, conditionType)
+		if condition == nil {
+			summary.Status = metav1.ConditionUnknown
+			summary.Reason = ReasonNotObserved
+			summary.Message = fmt.Sprintf("Condition %s is not set", conditionType)
+			break
+		}
+		if condition.Status == metav1.ConditionUnknown {
+			summary = summaryOf(metav1.ConditionUnknown, condition)
+			break
+		}
+	}
+	return Set(obj, summary)
+}
+
+// summaryOf returns a Ready condition with status that explains itself with
+// the reason and message of condition
+func summaryOf(status metav1.ConditionStatus, condition *metav1.Condition) metav1.Condition {
+	return metav1.Condition{
+		Type:    Ready,
+		Status:  status,
+		Reason:  condition.Reason,
+		Message: condition.Message,
+	}
+}
@@ -0,0 +1,226 @@
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"k8s-controller/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newApplication(generation int64, conditions ...metav1.Condition) *v1alpha1.Application {
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: generation}}
+	app.Status.Conditions = conditions
+	return app
+}
+
+func TestSet(t *testing.T) {
+	past := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	existing := metav1.Condition{
+		Type:               Progressing,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Scaling",
+		Message:            "Scaling to 2 replicas",
+		ObservedGeneration: 1,
+		LastTransitionTime: past,
+	}
+
+	tests := []struct {
+		name          string
+		generation    int64
+		condition     metav1.Condition
+		changed       bool
+		keepsTime     bool
+		conditionsLen int
+	}{
+		{
+			name:          "unchanged",
+			generation:    1,
+			condition:     metav1.Condition{Type: Progressing, Status: metav1.ConditionTrue, Reason: "Scaling", Message: "Scaling to 2 replicas"},
+			changed:       false,
+			keepsTime:     true,
+			conditionsLen: 1,
+		},
+		{
+			name:          "new message keeps transition time",
+			generation:    1,
+			condition:     metav1.Condition{Type: Progressing, Status: metav1.ConditionTrue, Reason: "Scaling", Message: "Scaling to 3 replicas"},
+			changed:       true,
+			keepsTime:     true,
+			conditionsLen: 1,
+		},
+		{
+			name:          "new generation keeps transition time",
+			generation:    2,
+			condition:     metav1.Condition{Type: Progressing, Status: metav1.ConditionTrue, Reason: "Scaling", Message: "Scaling to 2 replicas"},
+			changed:       true,
+			keepsTime:     true,
+			conditionsLen: 1,
+		},
+		{
+			name:          "new status sets transition time",
+			generation:    1,
+			condition:     metav1.Condition{Type: Progressing, Status: metav1.ConditionFalse, Reason: "Scaled", Message: "Scaled to 2 replicas"},
+			changed:       true,
+			keepsTime:     false,
+			conditionsLen: 1,
+		},
+		{
+			name:          "new type is appended",
+			generation:    1,
+			condition:     metav1.Condition{Type: Degraded, Status: metav1.ConditionFalse, Reason: "AsExpected"},
+			changed:       true,
+			keepsTime:     true,
+			conditionsLen: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := []metav1.Condition{existing}
+			app := newApplication(tt.generation, original...)
+
+			if changed := Set(app, tt.condition); changed != tt.changed {
+				t.Errorf("Expected changed to be %v, got %v", tt.changed, changed)
+			}
+			if len(app.Status.Conditions) != tt.conditionsLen {
+				t.Fatalf("Expected %d conditions, got %d", tt.conditionsLen, len(app.Status.Conditions))
+			}
+
+			got := Get(app, tt.condition.Type)
+			if got == nil || !Equal(*got, metav1.Condition{
+				Type:               tt.condition.Type,
+				Status:             tt.condition.Status,
+				Reason:             tt.condition.Reason,
+				Message:            tt.condition.Message,
+				ObservedGeneration: tt.generation,
+			}) {
+				t.Errorf("Expected condition %+v at generation %d, got %+v", tt.condition, tt.generation, got)
+			}
+			if got.LastTransitionTime.IsZero() {
+				t.Error("Expected a transition time")
+			}
+			if progressing := Get(app, Progressing); progressing.LastTransitionTime.Equal(&past) != tt.keepsTime {
+				t.Errorf("Expected keeping the transition time to be %v, got %s", tt.keepsTime, progressing.LastTransitionTime)
+			}
+			if original[0] != existing {
+				t.Error("Expected Set not to modify the original conditions slice")
+			}
+		})
+	}
+}
+
+func TestMarkAndQuery(t *testing.T) {
+	app := newApplication(3)
+
+	if !IsUnknown(app, Degraded) || IsTrue(app, Degraded) || IsFalse(app, Degraded) {
+		t.Error("Expected a missing condition to be Unknown only")
+	}
+
+	MarkTrue(app, Progressing, "Scaling", "Scaling to %d replicas", 2)
+	MarkFalse(app, Degraded, "AsExpected", "")
+	MarkUnknown(app, "Available", "Pending", "")
+
+	if !IsTrue(app, Progressing) || !IsFalse(app, Degraded) || !IsUnknown(app, "Available") {
+		t.Errorf("Unexpected conditions: %+v", app.Status.Conditions)
+	}
+	if got := Get(app, Progressing).Message; got != "Scaling to 2 replicas" {
+		t.Errorf("Expected a formatted message, got %q", got)
+	}
+	if !IsCurrent(app, Progressing) {
+		t.Error("Expected a condition set at the current generation to be current")
+	}
+
+	app.Generation = 4
+	if IsCurrent(app, Progressing) {
+		t.Error("Expected a condition of an older generation not to be current")
+	}
+
+	if !Delete(app, Progressing) || Get(app, Progressing) != nil || len(app.Status.Conditions) != 2 {
+		t.Errorf("Expected Progressing to be deleted, got %+v", app.Status.Conditions)
+	}
+	if Delete(app, Progressing) {
+		t.Error("Expected deleting a missing condition to report no change")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := metav1.Condition{Type: Ready, Status: metav1.ConditionTrue, Reason: ReasonReady, ObservedGeneration: 1}
+	b := a
+	b.LastTransitionTime = metav1.Now()
+	if !Equal(a, b) {
+		t.Error("Expected conditions differing only in transition time to be equal")
+	}
+
+	b.ObservedGeneration = 2
+	if Equal(a, b) {
+		t.Error("Expected conditions of different generations not to be equal")
+	}
+}
+
+func TestSetSummary(t *testing.T) {
+	condition := func(conditionType string, status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, Reason: reason, Message: reason + " message"}
+	}
+
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		status     metav1.ConditionStatus
+		reason     string
+	}{
+		{
+			name:       "all satisfied",
+			conditions: []metav1.Condition{condition("Available", metav1.ConditionTrue, "MinimumReplicas"), condition(Degraded, metav1.ConditionFalse, "AsExpected")},
+			status:     metav1.ConditionTrue,
+			reason:     ReasonReady,
+		},
+		{
+			name:       "degraded wins",
+			conditions: []metav1.Condition{condition("Available", metav1.ConditionFalse, "NoReplicas"), condition(Degraded, metav1.ConditionTrue, "ImagePullBackOff")},
+			status:     metav1.ConditionFalse,
+			reason:     "ImagePullBackOff",
+		},
+		{
+			name:       "false dependency",
+			conditions: []metav1.Condition{condition("Available", metav1.ConditionFalse, "NoReplicas"), condition(Progressing, metav1.ConditionTrue, "Scaling")},
+			status:     metav1.ConditionFalse,
+			reason:     "NoReplicas",
+		},
+		{
+			name:       "progressing",
+			conditions: []metav1.Condition{condition("Available", metav1.ConditionTrue, "MinimumReplicas"), condition(Progressing, metav1.ConditionTrue, "Scaling")},
+			status:     metav1.ConditionFalse,
+			reason:     "Scaling",
+		},
+		{
+			name:       "unknown dependency",
+			conditions: []metav1.Condition{condition("Available", metav1.ConditionUnknown, "Pending")},
+			status:     metav1.ConditionUnknown,
+			reason:     "Pending",
+		},
+		{
+			name:   "missing dependency",
+			status: metav1.ConditionUnknown,
+			reason: ReasonNotObserved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApplication(1, tt.conditions...)
+			if !SetSummary(app, "Available") {
+				t.Error("Expected the first summary to change the conditions")
+			}
+
+			ready := Get(app, Ready)
+			if ready == nil || ready.Status != tt.status || ready.Reason != tt.reason {
+				t.Errorf("Expected Ready %s with reason %s, got %+v", tt.status, tt.reason, ready)
+			}
+			if SetSummary(app, "Available") {
+				t.Error("Expected an unchanged summary to report no change")
+			}
+		})
+	}
+}
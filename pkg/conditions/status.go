@@ -0,0 +1,55 @@
+package conditions
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// UpdateFunc writes the status subresource of obj, with the signature of the
+// UpdateStatus methods of typed clients
+type UpdateFunc[T runtime.Object] func(ctx context.Context, obj T, opts metav1.UpdateOptions) (T, error)
+
+// UpdateStatus writes obj through update when its status differs from the
+// status of original, the object as read before reconciling, and returns the
+// stored object and whether it was written. An unchanged status costs no API
+// call and no new resourceVersion, which would trigger another reconcile.
+// Changes outside the status are ignored, since the status subresource does
+// not write them.
+//
+//	original, _ := lister.Deployments(namespace).Get(name)
+//	deployment := original.DeepCopy()
+//	deployment.Status.ObservedGeneration = deployment.Generation
+//	deployment, _, err = conditions.UpdateStatus(ctx, original, deployment, clientset.AppsV1().Deployments(namespace).UpdateStatus)
+func UpdateStatus[T runtime.Object](ctx context.Context, original, obj T, update UpdateFunc[T]) (T, bool, error) {
+	if equality.Semantic.DeepEqual(status(original), status(obj)) {
+		return obj, false, nil
+	}
+
+	updated, err := update(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		return obj, false, fmt.Errorf("failed to update status: %w", err)
+	}
+	return updated, true, nil
+}
+
+// status returns the Status field of a typed object or the status of an
+// unstructured one, such as those of the dynamic client, and falls back to
+// the whole object for types without a status
+func status(obj runtime.Object) any {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.Object["status"]
+	}
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() == reflect.Struct {
+		if field := v.FieldByName("Status"); field.IsValid() {
+			return field.Interface()
+		}
+	}
+	return obj
+}
@@ -0,0 +1,97 @@
+package conditions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestUpdateStatus(t *testing.T) {
+	original := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	clientset := fake.NewClientset(original)
+	deployments := clientset.AppsV1().Deployments("default")
+
+	// An unchanged copy is not written
+	_, written, err := UpdateStatus(context.Background(), original, original.DeepCopy(), deployments.UpdateStatus)
+	if err != nil || written {
+		t.Errorf("Expected no write for an unchanged status, got written=%v err=%v", written, err)
+	}
+	// Changes outside the status are not written through the subresource
+	relabeled := original.DeepCopy()
+	relabeled.Labels = map[string]string{"app": "web"}
+	_, written, err = UpdateStatus(context.Background(), original, relabeled, deployments.UpdateStatus)
+	if err != nil || written {
+		t.Errorf("Expected no write for a change outside the status, got written=%v err=%v", written, err)
+	}
+	if len(clientset.Actions()) != 0 {
+		t.Errorf("Expected no API calls, got %v", clientset.Actions())
+	}
+
+	changed := original.DeepCopy()
+	changed.Status.ReadyReplicas = 2
+	updated, written, err := UpdateStatus(context.Background(), original, changed, deployments.UpdateStatus)
+	if err != nil || !written {
+		t.Fatalf("Expected the changed status to be written, got written=%v err=%v", written, err)
+	}
+	if updated.Status.ReadyReplicas != 2 {
+		t.Errorf("Expected the stored object to be returned, got %+v", updated.Status)
+	}
+	actions := clientset.Actions()
+	if len(actions) != 1 || actions[0].GetVerb() != "update" || actions[0].GetSubresource() != "status" {
+		t.Errorf("Expected one status update, got %v", actions)
+	}
+}
+
+func TestUpdateStatusUnstructured(t *testing.T) {
+	original := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "web", "generation": int64(2)},
+		"status":   map[string]any{"readyReplicas": int64(1)},
+	}}
+	calls := 0
+	update := func(ctx context.Context, obj *unstructured.Unstructured, opts metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+		calls++
+		return obj, nil
+	}
+
+	relabeled := original.DeepCopy()
+	relabeled.SetLabels(map[string]string{"app": "web"})
+	if _, written, _ := UpdateStatus(context.Background(), original, relabeled, update); written {
+		t.Error("Expected no write for a change outside the status")
+	}
+
+	changed := original.DeepCopy()
+	if err := unstructured.SetNestedField(changed.Object, int64(2), "status", "readyReplicas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, written, err := UpdateStatus(context.Background(), original, changed, update); err != nil || !written {
+		t.Errorf("Expected the changed status to be written, got written=%v err=%v", written, err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected one update call, got %d", calls)
+	}
+}
+
+func TestUpdateStatusError(t *testing.T) {
+	original := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	clientset := fake.NewClientset(original)
+	clientset.PrependReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("conflict")
+	})
+
+	changed := original.DeepCopy()
+	changed.Status.ReadyReplicas = 1
+	got, written, err := UpdateStatus(context.Background(), original, changed, clientset.AppsV1().Deployments("default").UpdateStatus)
+	if err == nil || written {
+		t.Errorf("Expected the update error, got written=%v err=%v", written, err)
+	}
+	if got != changed {
+		t.Error("Expected the unwritten object to be returned on error")
+	}
+}
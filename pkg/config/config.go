@@ -1,42 +1,617 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"k8s-controller/pkg/logger"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// EnvPrefix is the prefix of every environment variable read by LoadConfig
+const EnvPrefix = "K8S_CONTROLLER"
+
 // Config holds all configuration for the application
 type Config struct {
-	LogLevel   string `mapstructure:"log_level"`
+	LogLevel string `mapstructure:"log_level"`
+	// LogFormat is console, json or logfmt; empty selects json unless stdout is a terminal
+	LogFormat  string `mapstructure:"log_format"`
 	KubeConfig string `mapstructure:"kubeconfig"`
 	Namespace  string `mapstructure:"namespace"`
+	// Workers is the default number of workers per controller
+	Workers int `mapstructure:"workers"`
+	// Controllers selects which registered controllers run ("*" for all, "-name" to disable one)
+	Controllers []string `mapstructure:"controllers"`
+	// ControllerWorkers overrides the global worker count per controller name
+	ControllerWorkers map[string]int `mapstructure:"controller_workers"`
+	// LeaderElection configures Lease-based leader election
+	LeaderElection LeaderElectionConfig `mapstructure:"leader_election"`
+	// MetricsBindAddress is the address of the controller's metrics endpoint ("0" disables it)
+	MetricsBindAddress string `mapstructure:"metrics_bind_address"`
+	// HealthProbeBindAddress is the address of the controller's health endpoint ("0" disables it)
+	HealthProbeBindAddress string `mapstructure:"health_probe_bind_address"`
+	// ShutdownTimeout bounds how long in-flight work may drain after a shutdown signal
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// LogLevelEndpoint serves the unauthenticated /debug/loglevel endpoint
+	LogLevelEndpoint bool `mapstructure:"loglevel_endpoint"`
+	// Server configures the standalone HTTP server
+	Server ServerConfig `mapstructure:"server"`
+	// Logging configures HTTP request logging
+	Logging LoggingConfig `mapstructure:"logging"`
+	// LogFile writes logs to a rotating file in addition to, or instead of, stdout
+	LogFile LogFileConfig `mapstructure:"log_file"`
+	// LogSampling thins out repeated log lines on hot paths
+	LogSampling LogSamplingConfig `mapstructure:"log_sampling"`
+	// RateLimit configures the retry backoff of the controller workqueues
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	// Tracing configures OpenTelemetry trace export
+	Tracing TracingConfig `mapstructure:"tracing"`
+
+	// sources records where each key's value came from
+	sources map[string]Source
 }
 
-// LoadConfig initializes and loads configuration from environment variables and flags
-func LoadConfig() (*Config, error) {
-	// Set up Viper with defaults
-	v := viper.New()
+// LeaderElectionConfig holds leader election settings
+type LeaderElectionConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	LeaseDuration  time.Duration `mapstructure:"lease_duration"`
+	RenewDeadline  time.Duration `mapstructure:"renew_deadline"`
+	RetryPeriod    time.Duration `mapstructure:"retry_period"`
+	LeaseName      string        `mapstructure:"lease_name"`
+	LeaseNamespace string        `mapstructure:"lease_namespace"`
+}
+
+// ServerConfig holds HTTP server settings
+type ServerConfig struct {
+	Port  int  `mapstructure:"port"`
+	Debug bool `mapstructure:"debug"`
+}
+
+var (
+	// DefaultRedactHeaders are the headers whose values are never logged
+	DefaultRedactHeaders = []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-Api-Key",
+		"X-Auth-Token",
+	}
+
+	// DefaultRedactKeys are the JSON and form body keys and query parameters
+	// whose values are never logged
+	DefaultRedactKeys = []string{
+		"*password*",
+		"*secret*",
+		"*token*",
+		"*api_key*",
+		"*apikey*",
+		"authorization",
+		"signature",
+	}
+)
+
+// LoggingConfig holds HTTP request logging settings
+type LoggingConfig struct {
+	SkipPaths       []string `mapstructure:"skip_paths"`
+	LogHeaders      bool     `mapstructure:"log_headers"`
+	LogRequestBody  bool     `mapstructure:"log_request_body"`
+	LogResponseBody bool     `mapstructure:"log_response_body"`
+	MaxBodyLogSize  int      `mapstructure:"max_body_log_size"`
+	LogTiming       bool     `mapstructure:"log_timing"`
+	// RedactHeaders, RedactBodyKeys and RedactQueryParams are case-insensitive
+	// glob patterns of header names, JSON and form body keys and query parameters
+	// whose values are masked in request logs
+	RedactHeaders     []string `mapstructure:"redact_headers"`
+	RedactBodyKeys    []string `mapstructure:"redact_body_keys"`
+	RedactQueryParams []string `mapstructure:"redact_query_params"`
+}
+
+// LogFileConfig holds rotating log file settings. Logs go to stdout only
+// while Path is empty.
+type LogFileConfig struct {
+	Path string `mapstructure:"path"`
+	// Format is console, json or logfmt
+	Format     string `mapstructure:"format"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxAgeDays int    `mapstructure:"max_age_days"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	Compress   bool   `mapstructure:"compress"`
+	// RotateInterval additionally rotates the file on a fixed schedule; zero disables it
+	RotateInterval time.Duration `mapstructure:"rotate_interval"`
+	// Stdout keeps logging to stdout next to the file
+	Stdout bool `mapstructure:"stdout"`
+}
+
+// LogSamplingConfig holds log sampling policies per level ("info") and per
+// message. A message policy takes precedence over the policy of its level.
+type LogSamplingConfig struct {
+	Levels   map[string]SamplingPolicy `mapstructure:"levels"`
+	Messages []MessageSamplingPolicy   `mapstructure:"messages"`
+}
+
+// SamplingPolicy writes the first Burst lines of every Period, then every
+// Thereafter-th line; 0 drops the rest of the period
+type SamplingPolicy struct {
+	Burst      int           `mapstructure:"burst"`
+	Period     time.Duration `mapstructure:"period"`
+	Thereafter int           `mapstructure:"thereafter"`
+}
+
+// MessageSamplingPolicy is a sampling policy for one log message. Messages
+// are a list rather than a map because map keys lose their case.
+type MessageSamplingPolicy struct {
+	Message    string        `mapstructure:"message"`
+	Burst      int           `mapstructure:"burst"`
+	Period     time.Duration `mapstructure:"period"`
+	Thereafter int           `mapstructure:"thereafter"`
+}
+
+// Policy returns the sampling policy without the message
+func (m MessageSamplingPolicy) Policy() SamplingPolicy {
+	return SamplingPolicy{Burst: m.Burst, Period: m.Period, Thereafter: m.Thereafter}
+}
+
+// RateLimitConfig holds the workqueue retry settings: per-key exponential
+// backoff between BaseDelay and MaxDelay, capped overall by QPS and Burst
+type RateLimitConfig struct {
+	BaseDelay time.Duration `mapstructure:"base_delay"`
+	MaxDelay  time.Duration `mapstructure:"max_delay"`
+	QPS       float64       `mapstructure:"qps"`
+	Burst     int           `mapstructure:"burst"`
+}
+
+// TracingConfig holds OpenTelemetry settings. Spans are exported over OTLP
+// only while Enabled is set.
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the collector's host:port; empty uses OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint string `mapstructure:"endpoint"`
+	// Protocol is grpc or http
+	Protocol string `mapstructure:"protocol"`
+	Insecure bool   `mapstructure:"insecure"`
+	// Headers are sent with every export, e.g. an API key
+	Headers     map[string]string `mapstructure:"headers" secret:"true"`
+	ServiceName string            `mapstructure:"service_name"`
+	// SampleRatio is the fraction of new traces that are sampled
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// Source identifies where the value of a configuration key came from
+type Source string
+
+// Configuration sources, from lowest to highest precedence
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
 
-	// Set default values
+// loader collects the sources read by LoadConfig
+type loader struct {
+	v      *viper.Viper
+	env    bool
+	strict bool
+	flags  map[string]*pflag.Flag
+}
+
+// Option customizes how LoadConfig gathers configuration
+type Option func(l *loader) error
+
+// WithConfigFile reads a YAML, TOML or JSON file, detected by its extension.
+// An empty path is ignored.
+func WithConfigFile(path string) Option {
+	return func(l *loader) error {
+		if path == "" {
+			return nil
+		}
+		l.v.SetConfigFile(path)
+		if err := l.v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// WithFlags binds command line flags to configuration keys. The map goes from
+// key (e.g. "server.port") to flag name (e.g. "port"); keys whose flag is not
+// part of the flag set are skipped. A flag only overrides other sources when
+// it was set explicitly.
+func WithFlags(flags *pflag.FlagSet, bindings map[string]string) Option {
+	return func(l *loader) error {
+		for key, name := range bindings {
+			flag := flags.Lookup(name)
+			if flag == nil {
+				continue
+			}
+			if err := l.v.BindPFlag(key, flag); err != nil {
+				return fmt.Errorf("failed to bind flag %s: %w", name, err)
+			}
+			l.flags[key] = flag
+		}
+		return nil
+	}
+}
+
+// WithoutEnv ignores environment variables
+func WithoutEnv() Option {
+	return func(l *loader) error {
+		l.env = false
+		return nil
+	}
+}
+
+// WithStrict rejects keys that do not match any configuration field, such
+// as misspelled keys in the config file
+func WithStrict() Option {
+	return func(l *loader) error {
+		l.strict = true
+		return nil
+	}
+}
+
+// setDefaults registers the default value of every configuration key. Every
+// key needs a default so it can also be read from the environment.
+func setDefaults(v *viper.Viper) {
 	v.SetDefault("log_level", "info")
+	v.SetDefault("log_format", "")
+	v.SetDefault("log_file.path", "")
+	v.SetDefault("log_file.format", "json")
+	v.SetDefault("log_file.max_size_mb", 100)
+	v.SetDefault("log_file.max_age_days", 0)
+	v.SetDefault("log_file.max_backups", 5)
+	v.SetDefault("log_file.compress", true)
+	v.SetDefault("log_file.rotate_interval", time.Duration(0))
+	v.SetDefault("log_file.stdout", true)
+	v.SetDefault("log_sampling.levels", map[string]interface{}{})
+	v.SetDefault("log_sampling.messages", []interface{}{})
 	v.SetDefault("kubeconfig", "")
 	v.SetDefault("namespace", "")
+	v.SetDefault("workers", 2)
+	v.SetDefault("controllers", []string{"*"})
+	v.SetDefault("controller_workers", map[string]int{})
+	v.SetDefault("leader_election.enabled", false)
+	v.SetDefault("leader_election.lease_duration", 15*time.Second)
+	v.SetDefault("leader_election.renew_deadline", 10*time.Second)
+	v.SetDefault("leader_election.retry_period", 2*time.Second)
+	v.SetDefault("leader_election.lease_name", "k8s-controller")
+	v.SetDefault("leader_election.lease_namespace", "")
+	v.SetDefault("metrics_bind_address", ":8080")
+	v.SetDefault("health_probe_bind_address", ":8081")
+	v.SetDefault("shutdown_timeout", 30*time.Second)
+	v.SetDefault("loglevel_endpoint", false)
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.debug", false)
+	v.SetDefault("logging.skip_paths", []string{"/health", "/readyz", "/metrics"})
+	v.SetDefault("logging.log_headers", false)
+	v.SetDefault("logging.log_request_body", false)
+	v.SetDefault("logging.log_response_body", false)
+	v.SetDefault("logging.max_body_log_size", 1024)
+	v.SetDefault("logging.log_timing", true)
+	v.SetDefault("logging.redact_headers", DefaultRedactHeaders)
+	v.SetDefault("logging.redact_body_keys", DefaultRedactKeys)
+	v.SetDefault("logging.redact_query_params", DefaultRedactKeys)
+	v.SetDefault("rate_limit.base_delay", 5*time.Millisecond)
+	v.SetDefault("rate_limit.max_delay", 1000*time.Second)
+	v.SetDefault("rate_limit.qps", 10.0)
+	v.SetDefault("rate_limit.burst", 100)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.protocol", "grpc")
+	v.SetDefault("tracing.insecure", false)
+	v.SetDefault("tracing.headers", map[string]string{})
+	v.SetDefault("tracing.service_name", "k8s-controller")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+}
 
-	// Environment variables
-	v.SetEnvPrefix("K8S_CONTROLLER")
-	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
-	v.AutomaticEnv()
+// LoadConfig loads configuration with the precedence
+// flag > environment variable > config file > default
+func LoadConfig(opts ...Option) (*Config, error) {
+	// Set up Viper with defaults
+	l := &loader{
+		v:     viper.New(),
+		env:   true,
+		flags: make(map[string]*pflag.Flag),
+	}
+	setDefaults(l.v)
+
+	// Config file and flags
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+
+	// Environment variables, nested keys use underscores
+	// (e.g. K8S_CONTROLLER_LEADER_ELECTION_LEASE_NAME)
+	if l.env {
+		l.v.SetEnvPrefix(EnvPrefix)
+		l.v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+		l.v.AutomaticEnv()
+	}
 
 	// Bind configuration to struct
 	var config Config
-	if err := v.Unmarshal(&config); err != nil {
+	if err := l.v.Unmarshal(&config, func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = l.strict
+	}); err != nil {
 		return nil, err
 	}
+	config.sources = l.sources()
 
 	return &config, nil
 }
 
+// sources reports where the value of every configuration key came from
+func (l *loader) sources() map[string]Source {
+	sources := make(map[string]Source)
+	for _, key := range l.v.AllKeys() {
+		switch {
+		case l.flags[key] != nil && l.flags[key].Changed:
+			sources[key] = SourceFlag
+		case l.env && isEnvSet(key):
+			sources[key] = SourceEnv
+		case l.v.InConfig(key):
+			sources[key] = SourceFile
+		default:
+			sources[key] = SourceDefault
+		}
+	}
+	return sources
+}
+
+// EnvVar returns the environment variable read for a configuration key
+func EnvVar(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// isEnvSet mirrors viper, which ignores empty environment variables
+func isEnvSet(key string) bool {
+	return os.Getenv(EnvVar(key)) != ""
+}
+
+// sourceRank orders sources by precedence
+var sourceRank = map[Source]int{SourceDefault: 0, SourceFile: 1, SourceEnv: 2, SourceFlag: 3}
+
+// Source returns where the value of key came from. For maps such as
+// controller_workers it returns the highest precedence source of any entry.
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	source := SourceDefault
+	for k, s := range c.sources {
+		if strings.HasPrefix(k, key+".") && sourceRank[s] > sourceRank[source] {
+			source = s
+		}
+	}
+	return source
+}
+
+// FieldError describes an invalid configuration value by its key path
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError aggregates every problem found by Validate
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "invalid configuration:\n  " + strings.Join(msgs, "\n  ")
+}
+
+// Unwrap exposes the field errors to errors.Is and errors.As
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Validate checks every setting and returns a *ValidationError listing all
+// invalid fields, or nil when the configuration is valid
+func (c *Config) Validate() error {
+	var errs []*FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !logger.LogLevel(c.LogLevel).IsValid() {
+		add("log_level", "must be one of trace, debug, info, warn, error, got %q", c.LogLevel)
+	}
+	if c.LogFormat != "" && !logger.LogFormat(c.LogFormat).IsValid() {
+		add("log_format", "must be one of console, json, logfmt, got %q", c.LogFormat)
+	}
+	for level, policy := range c.LogSampling.Levels {
+		field := "log_sampling.levels." + level
+		if !logger.LogLevel(level).IsValid() {
+			add(field, "must be one of trace, debug, info, warn, error")
+			continue
+		}
+		validateSamplingPolicy(add, field, policy)
+	}
+	for i, policy := range c.LogSampling.Messages {
+		field := fmt.Sprintf("log_sampling.messages[%d]", i)
+		if policy.Message == "" {
+			add(field+".message", "must not be empty")
+		}
+		validateSamplingPolicy(add, field, policy.Policy())
+	}
+	if lf := c.LogFile; lf.Path != "" {
+		if !logger.LogFormat(lf.Format).IsValid() {
+			add("log_file.format", "must be one of console, json, logfmt, got %q", lf.Format)
+		}
+		if lf.MaxSizeMB < 1 {
+			add("log_file.max_size_mb", "must be at least 1")
+		}
+		if lf.MaxAgeDays < 0 {
+			add("log_file.max_age_days", "must not be negative")
+		}
+		if lf.MaxBackups < 0 {
+			add("log_file.max_backups", "must not be negative")
+		}
+		if lf.RotateInterval < 0 {
+			add("log_file.rotate_interval", "must not be negative")
+		}
+	}
+	if c.KubeConfig != "" {
+		if _, err := os.Stat(c.KubeConfig); err != nil {
+			add("kubeconfig", "file %s is not readable", c.KubeConfig)
+		}
+	}
+	if c.ShutdownTimeout <= 0 {
+		add("shutdown_timeout", "must be positive")
+	}
+
+	// Controllers
+	if c.Workers < 1 {
+		add("workers", "must be at least 1")
+	}
+	if len(c.Controllers) == 0 {
+		add("controllers", "must not be empty, use \"*\" to run all controllers")
+	}
+	for i, name := range c.Controllers {
+		if strings.TrimPrefix(name, "-") == "" {
+			add(fmt.Sprintf("controllers[%d]", i), "must not be empty")
+		}
+	}
+	for name, workers := range c.ControllerWorkers {
+		if workers < 1 {
+			add("controller_workers."+name, "must be at least 1")
+		}
+	}
+	validateBindAddress(add, "metrics_bind_address", c.MetricsBindAddress)
+	validateBindAddress(add, "health_probe_bind_address", c.HealthProbeBindAddress)
+
+	// Leader election, with the same constraints client-go enforces
+	le := c.LeaderElection
+	if le.LeaseDuration <= 0 {
+		add("leader_election.lease_duration", "must be positive")
+	}
+	if le.RenewDeadline <= 0 {
+		add("leader_election.renew_deadline", "must be positive")
+	} else if le.RenewDeadline >= le.LeaseDuration {
+		add("leader_election.renew_deadline", "must be less than leader_election.lease_duration")
+	}
+	if le.RetryPeriod <= 0 {
+		add("leader_election.retry_period", "must be positive")
+	} else if float64(le.RetryPeriod)*1.2 >= float64(le.RenewDeadline) {
+		add("leader_election.retry_period", "must be less than leader_election.renew_deadline / 1.2")
+	}
+	if le.Enabled && le.LeaseName == "" {
+		add("leader_election.lease_name", "must not be empty when leader election is enabled")
+	}
+
+	// HTTP server
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port", "must be 1-65535, got %d", c.Server.Port)
+	}
+	if c.Logging.MaxBodyLogSize < 0 {
+		add("logging.max_body_log_size", "must not be negative")
+	}
+	validatePatterns(add, "logging.redact_headers", c.Logging.RedactHeaders)
+	validatePatterns(add, "logging.redact_body_keys", c.Logging.RedactBodyKeys)
+	validatePatterns(add, "logging.redact_query_params", c.Logging.RedactQueryParams)
+
+	// Workqueue rate limits
+	if c.RateLimit.BaseDelay <= 0 {
+		add("rate_limit.base_delay", "must be positive")
+	}
+	if c.RateLimit.MaxDelay < c.RateLimit.BaseDelay {
+		add("rate_limit.max_delay", "must not be less than rate_limit.base_delay")
+	}
+	if c.RateLimit.QPS <= 0 {
+		add("rate_limit.qps", "must be positive")
+	}
+	if c.RateLimit.Burst < 1 {
+		add("rate_limit.burst", "must be at least 1")
+	}
+
+	// Tracing
+	if tr := c.Tracing; tr.Enabled {
+		if tr.Protocol != "grpc" && tr.Protocol != "http" {
+			add("tracing.protocol", "must be grpc or http, got %q", tr.Protocol)
+		}
+		if tr.ServiceName == "" {
+			add("tracing.service_name", "must not be empty when tracing is enabled")
+		}
+		if tr.SampleRatio < 0 || tr.SampleRatio > 1 {
+			add("tracing.sample_ratio", "must be between 0 and 1, got %v", tr.SampleRatio)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+	return &ValidationError{Errors: errs}
+}
+
+// validateSamplingPolicy checks a log sampling policy of field
+func validateSamplingPolicy(add func(field, format string, args ...interface{}), field string, policy SamplingPolicy) {
+	if policy.Period <= 0 {
+		add(field+".period", "must be positive")
+	}
+	if policy.Burst < 0 {
+		add(field+".burst", "must not be negative")
+	}
+	if policy.Thereafter < 0 {
+		add(field+".thereafter", "must not be negative")
+	}
+	if policy.Burst == 0 && policy.Thereafter == 0 {
+		add(field, "burst or thereafter must be positive, otherwise every line is dropped")
+	}
+}
+
+// validatePatterns checks that every entry of field is a valid glob pattern
+func validatePatterns(add func(field, format string, args ...interface{}), field string, patterns []string) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			add(field, "invalid pattern %q", pattern)
+		}
+	}
+}
+
+// validateBindAddress checks a host:port listen address; "" and "0" disable
+// the listener
+func validateBindAddress(add func(field, format string, args ...interface{}), field, addr string) {
+	if addr == "" || addr == "0" {
+		return
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		add(field, "must be host:port or \"0\", got %q", addr)
+		return
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		add(field, "port must be 0-65535, got %q", port)
+	}
+}
+
 // SetConfigValue allows setting a configuration value at runtime
 func SetConfigValue(key string, value interface{}) {
 	viper.Set(key, value)
@@ -1,9 +1,16 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 	
+
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -55,6 +62,22 @@ func TestLoadConfigDefaults(t *testing.T) {
 	if cfg.Namespace != "" {
 		t.Errorf("Expected default Namespace to be empty, got %s", cfg.Namespace)
 	}
+
+	if cfg.MetricsBindAddress != ":8080" {
+		t.Errorf("Expected default MetricsBindAddress to be ':8080', got %s", cfg.MetricsBindAddress)
+	}
+
+	if cfg.HealthProbeBindAddress != ":8081" {
+		t.Errorf("Expected default HealthProbeBindAddress to be ':8081', got %s", cfg.HealthProbeBindAddress)
+	}
+
+	if cfg.ShutdownTimeout != 30*time.Second {
+		t.Errorf("Expected default ShutdownTimeout to be 30s, got %s", cfg.ShutdownTimeout)
+	}
+
+	if cfg.LogLevelEndpoint {
+		t.Error("Expected the log level endpoint to be disabled by default")
+	}
 }
 
 func TestSetConfigValue(t *testing.T) {
@@ -65,4 +88,448 @@ func TestSetConfigValue(t *testing.T) {
 	if val := viper.GetString("log_level"); val != "trace" {
 		t.Errorf("Expected log_level to be 'trace', got %s", val)
 	}
-}
\ No newline at end of file
+}
+func TestLoadConfigControllers(t *testing.T) {
+	// Default enables every controller
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(cfg.Controllers) != 1 || cfg.Controllers[0] != "*" {
+		t.Errorf("Expected default Controllers to be [*], got %v", cfg.Controllers)
+	}
+
+	// Comma separated list from the environment
+	t.Setenv("K8S_CONTROLLER_CONTROLLERS", "*,-deployment")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(cfg.Controllers) != 2 || cfg.Controllers[1] != "-deployment" {
+		t.Errorf("Expected Controllers to be [* -deployment], got %v", cfg.Controllers)
+	}
+}
+
+func TestLoadConfigLeaderElection(t *testing.T) {
+	t.Setenv("K8S_CONTROLLER_LEADER_ELECTION_ENABLED", "true")
+	t.Setenv("K8S_CONTROLLER_LEADER_ELECTION_LEASE_DURATION", "30s")
+	t.Setenv("K8S_CONTROLLER_LEADER_ELECTION_LEASE_NAMESPACE", "kube-system")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	le := cfg.LeaderElection
+	if !le.Enabled {
+		t.Error("Expected leader election to be enabled")
+	}
+	if le.LeaseDuration != 30*time.Second {
+		t.Errorf("Expected LeaseDuration to be 30s, got %s", le.LeaseDuration)
+	}
+	if le.RenewDeadline != 10*time.Second {
+		t.Errorf("Expected default RenewDeadline to be 10s, got %s", le.RenewDeadline)
+	}
+	if le.LeaseName != "k8s-controller" {
+		t.Errorf("Expected default LeaseName to be 'k8s-controller', got %s", le.LeaseName)
+	}
+	if le.LeaseNamespace != "kube-system" {
+		t.Errorf("Expected LeaseNamespace to be 'kube-system', got %s", le.LeaseNamespace)
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `log_level: debug
+workers: 4
+leader_election:
+  lease_name: from-file
+server:
+  port: 9090
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `log_level = "debug"
+workers = 4
+
+[leader_election]
+lease_name = "from-file"
+
+[server]
+port = 9090
+`,
+		},
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"log_level": "debug", "workers": 4, "leader_election": {"lease_name": "from-file"}, "server": {"port": 9090}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(WithConfigFile(writeConfigFile(t, tt.file, tt.content)))
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if cfg.LogLevel != "debug" {
+				t.Errorf("Expected LogLevel to be 'debug', got %s", cfg.LogLevel)
+			}
+			if cfg.Workers != 4 {
+				t.Errorf("Expected Workers to be 4, got %d", cfg.Workers)
+			}
+			if cfg.LeaderElection.LeaseName != "from-file" {
+				t.Errorf("Expected LeaseName to be 'from-file', got %s", cfg.LeaderElection.LeaseName)
+			}
+			if cfg.LeaderElection.LeaseDuration != 15*time.Second {
+				t.Errorf("Expected default LeaseDuration to be 15s, got %s", cfg.LeaderElection.LeaseDuration)
+			}
+			if cfg.Server.Port != 9090 {
+				t.Errorf("Expected Server.Port to be 9090, got %d", cfg.Server.Port)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `log_level: debug
+namespace: from-file
+workers: 4
+server:
+  port: 9090
+`)
+	t.Setenv("K8S_CONTROLLER_NAMESPACE", "from-env")
+	t.Setenv("K8S_CONTROLLER_SERVER_PORT", "7070")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("log-level", "info", "")
+	flags.String("namespace", "", "")
+	flags.Int("workers", 2, "")
+	flags.Int("port", 8080, "")
+	if err := flags.Parse([]string{"--namespace=from-flag"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	bindings := map[string]string{
+		"log_level":   "log-level",
+		"namespace":   "namespace",
+		"workers":     "workers",
+		"server.port": "port",
+		"server.none": "missing-flag",
+	}
+
+	cfg, err := LoadConfig(WithConfigFile(path), WithFlags(flags, bindings))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Flag beats environment and file
+	if cfg.Namespace != "from-flag" {
+		t.Errorf("Expected Namespace to be 'from-flag', got %s", cfg.Namespace)
+	}
+	// Environment beats file and unset flag default
+	if cfg.Server.Port != 7070 {
+		t.Errorf("Expected Server.Port to be 7070, got %d", cfg.Server.Port)
+	}
+	// File beats unset flag default
+	if cfg.LogLevel != "debug" {
+		t.Errorf("Expected LogLevel to be 'debug', got %s", cfg.LogLevel)
+	}
+	if cfg.Workers != 4 {
+		t.Errorf("Expected Workers to be 4, got %d", cfg.Workers)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	if _, err := LoadConfig(WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		fields []string
+	}{
+		{
+			name:   "defaults",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:   "log level",
+			modify: func(cfg *Config) { cfg.LogLevel = "loud" },
+			fields: []string{"log_level"},
+		},
+		{
+			name: "server port and workers",
+			modify: func(cfg *Config) {
+				cfg.Server.Port = 70000
+				cfg.Workers = 0
+				cfg.ControllerWorkers = map[string]int{"deployment": -1}
+			},
+			fields: []string{"controller_workers.deployment", "server.port", "workers"},
+		},
+		{
+			name: "bind addresses",
+			modify: func(cfg *Config) {
+				cfg.MetricsBindAddress = "8080"
+				cfg.HealthProbeBindAddress = ":99999"
+			},
+			fields: []string{"health_probe_bind_address", "metrics_bind_address"},
+		},
+		{
+			name:   "disabled bind address",
+			modify: func(cfg *Config) { cfg.MetricsBindAddress = "0" },
+		},
+		{
+			name: "leader election timings",
+			modify: func(cfg *Config) {
+				cfg.LeaderElection.Enabled = true
+				cfg.LeaderElection.RenewDeadline = 20 * time.Second
+				cfg.LeaderElection.LeaseName = ""
+			},
+			fields: []string{"leader_election.lease_name", "leader_election.renew_deadline"},
+		},
+		{
+			name: "rate limits",
+			modify: func(cfg *Config) {
+				cfg.RateLimit.QPS = 0
+				cfg.RateLimit.MaxDelay = time.Millisecond
+			},
+			fields: []string{"rate_limit.max_delay", "rate_limit.qps"},
+		},
+		{
+			name: "log file",
+			modify: func(cfg *Config) {
+				cfg.LogFile.Path = "/var/log/k8s-controller.log"
+				cfg.LogFile.Format = "xml"
+				cfg.LogFile.MaxSizeMB = 0
+				cfg.LogFile.MaxBackups = -1
+			},
+			fields: []string{"log_file.format", "log_file.max_backups", "log_file.max_size_mb"},
+		},
+		{
+			name:   "log file settings ignored without path",
+			modify: func(cfg *Config) { cfg.LogFile.MaxSizeMB = 0 },
+		},
+		{
+			name: "log sampling",
+			modify: func(cfg *Config) {
+				cfg.LogSampling.Levels = map[string]SamplingPolicy{
+					"loud": {Burst: 1, Period: time.Second},
+					"info": {Burst: 10},
+				}
+				cfg.LogSampling.Messages = []MessageSamplingPolicy{{Period: time.Second}}
+			},
+			fields: []string{
+				"log_sampling.levels.info.period",
+				"log_sampling.levels.loud",
+				"log_sampling.messages[0]",
+				"log_sampling.messages[0].message",
+			},
+		},
+		{
+			name: "redaction patterns",
+			modify: func(cfg *Config) {
+				cfg.Logging.RedactHeaders = []string{"Authorization", "X-[bad"}
+			},
+			fields: []string{"logging.redact_headers"},
+		},
+		{
+			name: "tracing",
+			modify: func(cfg *Config) {
+				cfg.Tracing.Enabled = true
+				cfg.Tracing.Protocol = "zipkin"
+				cfg.Tracing.SampleRatio = 2
+			},
+			fields: []string{"tracing.protocol", "tracing.sample_ratio"},
+		},
+		{
+			name:   "missing kubeconfig",
+			modify: func(cfg *Config) { cfg.KubeConfig = filepath.Join(t.TempDir(), "missing") },
+			fields: []string{"kubeconfig"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			tt.modify(cfg)
+
+			err = cfg.Validate()
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Errorf("Expected config to be valid, got %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected a *ValidationError, got %v", err)
+			}
+			if len(verr.Errors) != len(tt.fields) {
+				t.Fatalf("Expected %d field errors, got %v", len(tt.fields), err)
+			}
+			for i, field := range tt.fields {
+				if verr.Errors[i].Field != field {
+					t.Errorf("Expected error %d to be for %s, got %s", i, field, verr.Errors[i].Field)
+				}
+				if !strings.Contains(err.Error(), field+": ") {
+					t.Errorf("Expected message to contain %q, got %v", field+": ", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigLogSampling(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `log_sampling:
+  levels:
+    info: {burst: 100, period: 1s, thereafter: 100}
+  messages:
+    - message: Request completed
+      burst: 10
+      period: 1s
+      thereafter: 50
+`)
+
+	cfg, err := LoadConfig(WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+
+	if got := cfg.LogSampling.Levels["info"]; got != (SamplingPolicy{Burst: 100, Period: time.Second, Thereafter: 100}) {
+		t.Errorf("Unexpected info policy: %+v", got)
+	}
+	expected := []MessageSamplingPolicy{{Message: "Request completed", Burst: 10, Period: time.Second, Thereafter: 50}}
+	if !reflect.DeepEqual(cfg.LogSampling.Messages, expected) {
+		t.Errorf("Expected message policies %+v, got %+v", expected, cfg.LogSampling.Messages)
+	}
+}
+
+func TestLoadConfigRedactionDefaults(t *testing.T) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// The defaults must match the ones the middleware uses without a config
+	if !reflect.DeepEqual(cfg.Logging.RedactHeaders, DefaultRedactHeaders) {
+		t.Errorf("Expected redact_headers %v, got %v", DefaultRedactHeaders, cfg.Logging.RedactHeaders)
+	}
+	if !reflect.DeepEqual(cfg.Logging.RedactBodyKeys, DefaultRedactKeys) {
+		t.Errorf("Expected redact_body_keys %v, got %v", DefaultRedactKeys, cfg.Logging.RedactBodyKeys)
+	}
+	if !reflect.DeepEqual(cfg.Logging.RedactQueryParams, DefaultRedactKeys) {
+		t.Errorf("Expected redact_query_params %v, got %v", DefaultRedactKeys, cfg.Logging.RedactQueryParams)
+	}
+}
+
+func TestValidateServerPortMessage(t *testing.T) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Server.Port = 0
+
+	var fieldErr *FieldError
+	if err := cfg.Validate(); !errors.As(err, &fieldErr) {
+		t.Fatalf("Expected a *FieldError, got %v", err)
+	}
+	if fieldErr.Error() != "server.port: must be 1-65535, got 0" {
+		t.Errorf("Unexpected message: %s", fieldErr.Error())
+	}
+}
+
+func TestLoadConfigSources(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `log_level: debug
+namespace: from-file
+controller_workers:
+  deployment: 4
+`)
+	t.Setenv("K8S_CONTROLLER_NAMESPACE", "from-env")
+	t.Setenv("K8S_CONTROLLER_SERVER_PORT", "7070")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("workers", 2, "")
+	flags.Bool("debug", false, "")
+	if err := flags.Parse([]string{"--workers=3"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	cfg, err := LoadConfig(WithConfigFile(path), WithFlags(flags, map[string]string{
+		"workers":      "workers",
+		"server.debug": "debug",
+	}))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := map[string]Source{
+		"log_level":          SourceFile,
+		"namespace":          SourceEnv,
+		"server.port":        SourceEnv,
+		"workers":            SourceFlag,
+		"server.debug":       SourceDefault,
+		"controller_workers": SourceFile,
+		"shutdown_timeout":   SourceDefault,
+	}
+	sources := cfg.Sources()
+	for key, source := range expected {
+		if sources[key] != source {
+			t.Errorf("Expected source of %s to be %s, got %s", key, source, sources[key])
+		}
+	}
+}
+
+func TestLoadConfigWithoutEnv(t *testing.T) {
+	t.Setenv("K8S_CONTROLLER_LOG_LEVEL", "debug")
+
+	cfg, err := LoadConfig(WithoutEnv())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.LogLevel != "info" {
+		t.Errorf("Expected LogLevel to ignore the environment, got %s", cfg.LogLevel)
+	}
+	if cfg.Source("log_level") != SourceDefault {
+		t.Errorf("Expected log_level source to be default, got %s", cfg.Source("log_level"))
+	}
+}
+
+func TestLoadConfigStrict(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "loglevel: debug\n")
+
+	if _, err := LoadConfig(WithConfigFile(path)); err != nil {
+		t.Errorf("Expected unknown keys to be ignored by default, got %v", err)
+	}
+	_, err := LoadConfig(WithConfigFile(path), WithStrict())
+	if err == nil || !strings.Contains(err.Error(), "loglevel") {
+		t.Errorf("Expected an error naming the unknown key, got %v", err)
+	}
+}
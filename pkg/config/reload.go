@@ -0,0 +1,235 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s-controller/pkg/logger"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce coalesces the burst of events editors and ConfigMap
+// updates produce for a single change
+const reloadDebounce = 100 * time.Millisecond
+
+// reloadableKeys are the keys, or key prefixes ending in ".", that a running
+// process applies without a restart
+var reloadableKeys = []string{"log_level", "log_sampling.", "logging.", "rate_limit."}
+
+// IsReloadable reports whether key can change without restarting the process
+func IsReloadable(key string) bool {
+	for _, k := range reloadableKeys {
+		if key == k || (strings.HasSuffix(k, ".") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Change describes a key whose value differs between two configurations
+type Change struct {
+	Key string
+	Old interface{}
+	New interface{}
+}
+
+// Diff lists the keys whose values differ between old and new, sorted by
+// key. Values of secret fields are redacted.
+func Diff(old, new *Config) []Change {
+	oldValues, newValues := flatten(old), flatten(new)
+	secrets := secretKeys(new)
+
+	var changes []Change
+	for key, value := range newValues {
+		if reflect.DeepEqual(oldValues[key], value) {
+			continue
+		}
+		change := Change{Key: key, Old: oldValues[key], New: value}
+		if secrets[key] {
+			change.Old, change.New = Redacted, Redacted
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// flatten returns every leaf value of cfg keyed by its dotted mapstructure path
+func flatten(cfg *Config) map[string]interface{} {
+	values := make(map[string]interface{})
+	walkStruct("", reflect.ValueOf(cfg).Elem(), func(key string, field reflect.StructField, value reflect.Value) {
+		values[key] = value.Interface()
+	})
+	return values
+}
+
+// secretKeys returns the keys of fields tagged `secret:"true"`
+func secretKeys(cfg *Config) map[string]bool {
+	secrets := make(map[string]bool)
+	walkStruct("", reflect.ValueOf(cfg).Elem(), func(key string, field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("secret") == "true" {
+			secrets[key] = true
+		}
+	})
+	return secrets
+}
+
+// walkStruct calls fn for every leaf field of v tagged with mapstructure
+func walkStruct(prefix string, v reflect.Value, fn func(key string, field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkStruct(prefix+key+".", field, fn)
+			continue
+		}
+		fn(prefix+key, t.Field(i), field)
+	}
+}
+
+// applyReloadable returns a copy of current with the reloadable settings
+// taken from next
+func applyReloadable(current, next *Config) *Config {
+	merged := *current
+	merged.LogLevel = next.LogLevel
+	merged.LogSampling = next.LogSampling
+	merged.Logging = next.Logging
+	merged.RateLimit = next.RateLimit
+	return &merged
+}
+
+// Watcher reloads a config file whenever it changes on disk and applies the
+// settings that are safe to change at runtime
+type Watcher struct {
+	path  string
+	load  func() (*Config, error)
+	apply func(cfg *Config, changes []Change)
+
+	mu      sync.Mutex
+	current *Config
+}
+
+// NewWatcher creates a watcher for the config file at path. current is the
+// configuration the process runs with, load re-reads the configuration from
+// all sources and apply is called with the new configuration and the
+// reloadable keys that changed after every reload that changes one.
+func NewWatcher(path string, current *Config, load func() (*Config, error), apply func(cfg *Config, changes []Change)) *Watcher {
+	return &Watcher{
+		path:    filepath.Clean(path),
+		load:    load,
+		apply:   apply,
+		current: current,
+	}
+}
+
+// Current returns the configuration applied by the last successful reload
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Reload re-reads the configuration and applies its reloadable settings.
+// An invalid configuration is rejected and the current one kept.
+func (w *Watcher) Reload() error {
+	next, err := w.load()
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("file", w.path).Msg("Rejected config reload")
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var applied []Change
+	for _, change := range Diff(w.current, next) {
+		if !IsReloadable(change.Key) {
+			logger.Warn().
+				Str("key", change.Key).
+				Interface("old", change.Old).
+				Interface("new", change.New).
+				Msg("Config change requires a restart, ignoring")
+			continue
+		}
+		logger.Info().
+			Str("key", change.Key).
+			Interface("old", change.Old).
+			Interface("new", change.New).
+			Msg("Config changed")
+		applied = append(applied, change)
+	}
+	if len(applied) == 0 {
+		logger.Debug().Str("file", w.path).Msg("Config reloaded without reloadable changes")
+		return nil
+	}
+
+	w.current = applyReloadable(w.current, next)
+	w.apply(w.current, applied)
+	logger.Info().Str("file", w.path).Msg("Config reloaded")
+	return nil
+}
+
+// Run watches the config file until ctx is cancelled. The parent directory
+// is watched so that files replaced by a rename, as editors and Kubernetes
+// ConfigMap volumes do, keep being picked up.
+func (w *Watcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch config file %s: %w", w.path, err)
+	}
+	logger.Info().Str("file", w.path).Msg("Watching config file for changes")
+
+	realPath, _ := filepath.EvalSymlinks(w.path)
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			// Symlinked files (ConfigMaps) change by swapping the link target
+			currentPath, _ := filepath.EvalSymlinks(w.path)
+			written := filepath.Clean(event.Name) == w.path &&
+				event.Has(fsnotify.Write|fsnotify.Create)
+			if written || (currentPath != "" && currentPath != realPath) {
+				realPath = currentPath
+				timer.Reset(reloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Error().Err(err).Str("file", w.path).Msg("Config watcher error")
+		case <-timer.C:
+			_ = w.Reload()
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s-controller/pkg/logger"
+)
+
+func TestIsReloadable(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"log_level", true},
+		{"logging.skip_paths", true},
+		{"rate_limit.qps", true},
+		{"log_sampling.messages", true},
+		{"namespace", false},
+		{"server.port", false},
+		{"logging", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := IsReloadable(tt.key); got != tt.expected {
+				t.Errorf("Expected IsReloadable(%q) to be %v, got %v", tt.key, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	next := *old
+	next.LogLevel = "debug"
+	next.Logging.SkipPaths = []string{"/healthz"}
+	next.Server.Port = 9090
+
+	changes := Diff(old, &next)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %v", changes)
+	}
+	keys := []string{"log_level", "logging.skip_paths", "server.port"}
+	for i, key := range keys {
+		if changes[i].Key != key {
+			t.Errorf("Expected change %d to be %s, got %s", i, key, changes[i].Key)
+		}
+	}
+	if changes[0].Old != "info" || changes[0].New != "debug" {
+		t.Errorf("Expected log_level change info -> debug, got %v -> %v", changes[0].Old, changes[0].New)
+	}
+
+	if changes := Diff(old, old); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+}
+
+// appliedConfig is a configuration passed to the apply function of a Watcher
+type appliedConfig struct {
+	cfg     *Config
+	changes []Change
+}
+
+func newTestWatcher(t *testing.T, content string) (string, *Watcher, chan appliedConfig) {
+	t.Helper()
+	path := writeConfigFile(t, "config.yaml", content)
+	current, err := LoadConfig(WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	applied := make(chan appliedConfig, 10)
+	w := NewWatcher(path, current, func() (*Config, error) {
+		return LoadConfig(WithConfigFile(path))
+	}, func(cfg *Config, changes []Change) {
+		applied <- appliedConfig{cfg: cfg, changes: changes}
+	})
+	return path, w, applied
+}
+
+func TestWatcherReload(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutput(buffer)
+
+	path, w, applied := newTestWatcher(t, "log_level: info\nnamespace: a\n")
+
+	// Reloadable change is applied, the namespace change is not
+	if err := os.WriteFile(path, []byte("log_level: debug\nnamespace: b\nrate_limit:\n  qps: 50\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Expected reload to succeed, got %v", err)
+	}
+	select {
+	case reload := <-applied:
+		cfg := reload.cfg
+		if cfg.LogLevel != "debug" || cfg.RateLimit.QPS != 50 {
+			t.Errorf("Expected log_level debug and qps 50, got %s and %v", cfg.LogLevel, cfg.RateLimit.QPS)
+		}
+		if cfg.Namespace != "a" {
+			t.Errorf("Expected namespace to stay 'a', got %s", cfg.Namespace)
+		}
+		var keys []string
+		for _, change := range reload.changes {
+			keys = append(keys, change.Key)
+		}
+		if !reflect.DeepEqual(keys, []string{"log_level", "rate_limit.qps"}) {
+			t.Errorf("Expected only the reloadable changes to be applied, got %v", keys)
+		}
+	default:
+		t.Fatal("Expected the new config to be applied")
+	}
+	if !bytes.Contains(buffer.Bytes(), []byte("requires a restart")) {
+		t.Error("Expected a warning for the namespace change")
+	}
+
+	// Invalid configuration is rejected
+	if err := os.WriteFile(path, []byte("log_level: loud\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := w.Reload(); err == nil {
+		t.Error("Expected reload of an invalid config to fail")
+	}
+	if len(applied) != 0 {
+		t.Error("Expected an invalid config not to be applied")
+	}
+	if w.Current().LogLevel != "debug" {
+		t.Errorf("Expected the current log level to stay 'debug', got %s", w.Current().LogLevel)
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	path, w, applied := newTestWatcher(t, "log_level: info\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+	// Give the watcher time to register
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("log_level: warn\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	select {
+	case reload := <-applied:
+		if reload.cfg.LogLevel != "warn" {
+			t.Errorf("Expected log_level to be 'warn', got %s", reload.cfg.LogLevel)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the config reload")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected Run to stop cleanly, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"time"
+)
+
+// Redacted replaces the value of secret fields in View
+const Redacted = "<redacted>"
+
+// View returns the configuration as nested maps keyed like the config file,
+// ready to be printed as YAML or JSON. Durations are formatted as strings
+// and fields tagged `secret:"true"` are redacted when set.
+func (c *Config) View() map[string]interface{} {
+	return viewStruct(reflect.ValueOf(c).Elem())
+}
+
+// Sources returns the source of every key listed by View, keyed by its
+// dotted path (e.g. "server.port")
+func (c *Config) Sources() map[string]Source {
+	sources := make(map[string]Source)
+	for key := range flatten(c) {
+		sources[key] = c.Source(key)
+	}
+	return sources
+}
+
+func viewStruct(v reflect.Value) map[string]interface{} {
+	view := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("secret") == "true":
+			if value.IsZero() {
+				view[key] = value.Interface()
+			} else {
+				view[key] = Redacted
+			}
+		default:
+			view[key] = viewValue(value)
+		}
+	}
+	return view
+}
+
+// viewValue formats a field value for View, descending into structs held in
+// maps and slices
+func viewValue(v reflect.Value) interface{} {
+	switch {
+	case v.Kind() == reflect.Struct:
+		return viewStruct(v)
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		return v.Interface().(time.Duration).String()
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.Struct:
+		view := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			view[iter.Key().String()] = viewValue(iter.Value())
+		}
+		return view
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		view := make([]interface{}, v.Len())
+		for i := range view {
+			view[i] = viewValue(v.Index(i))
+		}
+		return view
+	default:
+		return v.Interface()
+	}
+}
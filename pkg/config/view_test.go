@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestView(t *testing.T) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	view := cfg.View()
+	if view["log_level"] != "info" {
+		t.Errorf("Expected log_level to be 'info', got %v", view["log_level"])
+	}
+	if view["shutdown_timeout"] != "30s" {
+		t.Errorf("Expected shutdown_timeout to be formatted as '30s', got %v", view["shutdown_timeout"])
+	}
+	server, ok := view["server"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected server to be a nested map, got %T", view["server"])
+	}
+	if server["port"] != 8080 {
+		t.Errorf("Expected server.port to be 8080, got %v", server["port"])
+	}
+	cfg.LogSampling.Messages = []MessageSamplingPolicy{{Message: "hot", Burst: 1, Period: time.Second}}
+	sampling := cfg.View()["log_sampling"].(map[string]interface{})
+	messages, ok := sampling["messages"].([]interface{})
+	if !ok || len(messages) != 1 || messages[0].(map[string]interface{})["period"] != "1s" {
+		t.Errorf("Expected sampling policies with formatted periods, got %v", sampling["messages"])
+	}
+	if _, ok := view["sources"]; ok {
+		t.Error("Expected unexported fields to be left out")
+	}
+}
+
+func TestViewRedactsSecrets(t *testing.T) {
+	type credentials struct {
+		User     string            `mapstructure:"user"`
+		Password string            `mapstructure:"password" secret:"true"`
+		Headers  map[string]string `mapstructure:"headers" secret:"true"`
+		Token    string            `mapstructure:"token" secret:"true"`
+		Timeout  time.Duration     `mapstructure:"timeout"`
+	}
+
+	view := viewStruct(reflect.ValueOf(credentials{
+		User:     "admin",
+		Password: "hunter2",
+		Headers:  map[string]string{"Authorization": "Bearer x"},
+		Timeout:  time.Minute,
+	}))
+
+	expected := map[string]interface{}{
+		"user":     "admin",
+		"password": Redacted,
+		"headers":  Redacted,
+		"token":    "",
+		"timeout":  "1m0s",
+	}
+	if !reflect.DeepEqual(view, expected) {
+		t.Errorf("Expected %v, got %v", expected, view)
+	}
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test-token
+`
+
+func TestNewClientset(t *testing.T) {
+	// Write a minimal kubeconfig
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+
+	clientset, err := NewClientset(path)
+	if err != nil {
+		t.Fatalf("Failed to create clientset: %v", err)
+	}
+	if clientset == nil {
+		t.Error("Expected clientset to be non-nil")
+	}
+}
+
+func TestNewClientsetMissingFile(t *testing.T) {
+	_, err := NewClientset(filepath.Join(t.TempDir(), "does-not-exist"))
+	if err == nil {
+		t.Error("Expected an error for a missing kubeconfig file")
+	}
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/metrics"
+	"k8s-controller/pkg/tracing"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// maxRetries is the number of times a key is retried before it is dropped
+// from the queue
+const maxRetries = 5
+
+// Result tells the controller whether a key should be processed again
+type Result struct {
+	// Requeue puts the key back on the queue with rate-limited backoff
+	Requeue bool
+	// RequeueAfter puts the key back on the queue after the given delay
+	RequeueAfter time.Duration
+}
+
+// Reconciler handles a single namespace/name key taken from the workqueue
+type Reconciler interface {
+	Reconcile(ctx context.Context, key string) (Result, error)
+}
+
+// ReconcilerFunc adapts a plain function to the Reconciler interface
+type ReconcilerFunc func(ctx context.Context, key string) (Result, error)
+
+// Reconcile calls f(ctx, key)
+func (f ReconcilerFunc) Reconcile(ctx context.Context, key string) (Result, error) {
+	return f(ctx, key)
+}
+
+// Controller feeds informer events into a rate-limited workqueue and drains
+// it with a pool of workers calling the reconciler
+type Controller struct {
+	name       string
+	queue      workqueue.TypedRateLimitingInterface[string]
+	reconciler Reconciler
+	log        *zerolog.Logger
+	// synced reports whether the informer and those of owned children
+	// have synced
+	synced []cache.InformerSynced
+
+	// inFlight records when each key currently being reconciled was picked up
+	mu       sync.Mutex
+	inFlight map[string]time.Time
+}
+
+// New creates a controller that reconciles objects observed by the informer
+func New(name string, informer cache.SharedIndexInformer, reconciler Reconciler) (*Controller, error) {
+	c := &Controller{
+		name: name,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			newReloadableRateLimiter(),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: name},
+		),
+		reconciler: reconciler,
+		log:        componentLogger(name),
+		synced:     []cache.InformerSynced{informer.HasSynced},
+		inFlight:   make(map[string]time.Time),
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(_, newObj interface{}) {
+			c.enqueue(newObj)
+		},
+		DeleteFunc: c.enqueue,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add event handler for %s controller: %w", name, err)
+	}
+
+	return c, nil
+}
+
+// componentLogger returns the "controller.<name>" component logger, so the
+// level of each controller can be changed on its own
+func componentLogger(name string) *zerolog.Logger {
+	l := logger.Component("controller."+name).With().Str("controller", name).Logger()
+	return &l
+}
+
+// Name returns the controller name
+func (c *Controller) Name() string {
+	return c.name
+}
+
+// HasSynced reports whether the informer caches have completed their initial
+// list
+func (c *Controller) HasSynced() bool {
+	for _, synced := range c.synced {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckStuck returns an error if a reconcile has been running longer than
+// threshold, which usually means a worker is blocked
+func (c *Controller) CheckStuck(threshold time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, started := range c.inFlight {
+		if running := now.Sub(started); running > threshold {
+			return fmt.Errorf("%s controller has been reconciling %s for %s", c.name, key, running.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// Run waits for the informer caches to sync and starts the workers. It blocks
+// until the context is cancelled, then shuts down the queue and waits for
+// in-flight reconciles to finish. Keys still queued at that point are left
+// for the next run.
+func (c *Controller) Run(ctx context.Context, workers int) error {
+	c.log.Info().Msg("Waiting for informer caches to sync")
+	if !cache.WaitForCacheSync(ctx.Done(), c.synced...) {
+		c.queue.ShutDown()
+		if ctx.Err() != nil {
+			// Shutdown requested before the cache synced
+			return nil
+		}
+		return fmt.Errorf("failed to sync informer cache for %s controller", c.name)
+	}
+
+	c.log.Info().Int("workers", workers).Msg("Starting workers")
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.runWorker(ctx)
+		}()
+	}
+
+	<-ctx.Done()
+	c.log.Info().Msg("Shutting down workqueue, waiting for in-flight reconciles")
+	c.queue.ShutDown()
+	wg.Wait()
+	c.log.Info().Msg("Workers stopped")
+
+	return nil
+}
+
+// enqueue adds the namespace/name key of an object to the workqueue
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		c.log.Error().Err(err).Msg("Failed to build object key")
+		return
+	}
+	c.queue.Add(key)
+}
+
+// runWorker processes items until the queue is shut down
+func (c *Controller) runWorker(ctx context.Context) {
+	for c.processNextItem(ctx) {
+	}
+}
+
+// processNextItem reconciles a single key and requeues it according to the
+// result, or with backoff on error
+func (c *Controller) processNextItem(ctx context.Context) bool {
+	key, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	// Skip keys still queued once shutdown has started
+	if ctx.Err() != nil {
+		return true
+	}
+
+	start := time.Now()
+	c.mu.Lock()
+	c.inFlight[key] = start
+	c.mu.Unlock()
+
+	// A reconcile that already started is allowed to finish, so it does not
+	// inherit the cancellation of the run context. Reconcilers log through
+	// logger.FromContext to carry the controller name, key and trace ID.
+	reconcileCtx, span := tracing.Start(context.WithoutCancel(ctx), "Reconcile "+c.name,
+		trace.WithAttributes(attribute.String("controller", c.name), attribute.String("key", key)),
+	)
+	defer span.End()
+	keyLogger := tracing.WithTraceFields(reconcileCtx, c.log.With().Str("key", key)).Logger()
+	reconcileCtx = logger.IntoContext(reconcileCtx, &keyLogger)
+	result, err := c.reconciler.Reconcile(reconcileCtx, key)
+
+	c.mu.Lock()
+	delete(c.inFlight, key)
+	c.mu.Unlock()
+
+	observe := func(result string) {
+		metrics.ObserveReconcile(c.name, result, time.Since(start))
+		span.SetAttributes(attribute.String("result", result))
+	}
+
+	if err == nil {
+		switch {
+		case result.RequeueAfter > 0:
+			observe("requeue_after")
+			c.queue.Forget(key)
+			c.queue.AddAfter(key, result.RequeueAfter)
+		case result.Requeue:
+			observe("requeue")
+			c.queue.AddRateLimited(key)
+		default:
+			observe("success")
+			c.queue.Forget(key)
+		}
+		return true
+	}
+	observe("error")
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	if c.queue.NumRequeues(key) < maxRetries {
+		keyLogger.Warn().Err(err).Msg("Reconcile failed, requeuing")
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	keyLogger.Error().Err(err).Msg("Reconcile failed, dropping key")
+	c.queue.Forget(key)
+	return true
+}
@@ -0,0 +1,400 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/tracing"
+	"k8s-controller/pkg/tracing/tracingtest"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestDeployment(namespace, name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	}
+}
+
+// syncBuffer collects log output written concurrently by workers
+type syncBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.Write(p)
+}
+
+// Bytes returns a copy of the output written so far
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buffer.Bytes())
+}
+
+func (b *syncBuffer) String() string {
+	return string(b.Bytes())
+}
+
+func TestControllerReconcilesInformerEvents(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	// Fake clientset with one deployment inside and one outside the namespace
+	clientset := fake.NewClientset(
+		newTestDeployment("default", "web"),
+		newTestDeployment("other", "ignored"),
+	)
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace("default"))
+
+	keys := make(chan string, 10)
+	ctrl, err := New("test", factory.Apps().V1().Deployments().Informer(), ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+		keys <- key
+		return Result{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory.Start(ctx.Done())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := ctrl.Run(ctx, 2); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}()
+	// Stop the workers before the next test swaps the logger output
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	// Initial list should be reconciled
+	select {
+	case key := <-keys:
+		if key != "default/web" {
+			t.Errorf("Expected key 'default/web', got %s", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for initial reconcile")
+	}
+
+	// Objects created later should be reconciled too
+	_, err = clientset.AppsV1().Deployments("default").Create(ctx, newTestDeployment("default", "api"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create deployment: %v", err)
+	}
+
+	select {
+	case key := <-keys:
+		if key != "default/api" {
+			t.Errorf("Expected key 'default/api', got %s", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reconcile of created deployment")
+	}
+}
+
+func TestControllerRequeuesOnError(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	clientset := fake.NewClientset(newTestDeployment("default", "web"))
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+
+	var mu sync.Mutex
+	attempts := 0
+	done := make(chan struct{})
+
+	ctrl, err := New("test", factory.Apps().V1().Deployments().Informer(), ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			return Result{}, errors.New("transient error")
+		}
+		close(done)
+		return Result{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory.Start(ctx.Done())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := ctrl.Run(ctx, 1); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}()
+	// Stop the workers before the next test swaps the logger output
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reconcile to succeed after retries")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 {
+		t.Errorf("Expected 3 reconcile attempts, got %d", attempts)
+	}
+}
+
+func TestControllerWaitsForInFlightReconcile(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	clientset := fake.NewClientset(newTestDeployment("default", "web"))
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var reconcileErr error
+
+	ctrl, err := New("test", factory.Apps().V1().Deployments().Informer(), ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+		close(started)
+		<-release
+		reconcileErr = ctx.Err()
+		return Result{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory.Start(ctx.Done())
+
+	done := make(chan struct{})
+	go func() {
+		if err := ctrl.Run(ctx, 1); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		close(done)
+	}()
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	// Stop the workers before the next test swaps the logger output, even
+	// when the test fails early
+	defer func() {
+		cancel()
+		unblock()
+		<-done
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reconcile to start")
+	}
+
+	// Shutdown must wait for the in-flight reconcile
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("Run returned before the in-flight reconcile finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unblock()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for Run to return")
+	}
+
+	if reconcileErr != nil {
+		t.Errorf("Expected in-flight reconcile context to stay valid, got %v", reconcileErr)
+	}
+}
+
+func TestControllerCheckStuck(t *testing.T) {
+	clientset := fake.NewClientset()
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+
+	ctrl, err := New("test", factory.Apps().V1().Deployments().Informer(), ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+		return Result{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+
+	if err := ctrl.CheckStuck(time.Minute); err != nil {
+		t.Errorf("Expected no error without reconciles in flight, got %v", err)
+	}
+
+	// Simulate a reconcile that started long ago
+	ctrl.inFlight["default/web"] = time.Now().Add(-2 * time.Minute)
+	if err := ctrl.CheckStuck(time.Minute); err == nil {
+		t.Error("Expected an error for a reconcile running longer than the threshold")
+	}
+	if err := ctrl.CheckStuck(time.Hour); err != nil {
+		t.Errorf("Expected no error below the threshold, got %v", err)
+	}
+}
+
+func TestControllerScopesReconcileLogger(t *testing.T) {
+	buffer := new(syncBuffer)
+	logger.SetOutputFormat(buffer, logger.JSONFormat)
+	defer logger.SetOutputFormat(new(bytes.Buffer), logger.ConsoleFormat)
+
+	clientset := fake.NewClientset(newTestDeployment("default", "web"))
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+
+	done := make(chan struct{})
+	var once sync.Once
+	ctrl, err := New("test", factory.Apps().V1().Deployments().Informer(), ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+		logger.FromContext(ctx).Info().Msg("inside reconcile")
+		once.Do(func() { close(done) })
+		return Result{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory.Start(ctx.Done())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := ctrl.Run(ctx, 1); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}()
+
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reconcile")
+	}
+	cancel()
+	<-stopped
+
+	for _, line := range bytes.Split(buffer.Bytes(), []byte("\n")) {
+		if !bytes.Contains(line, []byte("inside reconcile")) {
+			continue
+		}
+		for _, field := range []string{`"controller":"test"`, `"key":"default/web"`} {
+			if !bytes.Contains(line, []byte(field)) {
+				t.Errorf("Expected reconcile log line to contain %s, got %s", field, line)
+			}
+		}
+		return
+	}
+	t.Errorf("Expected a log line from the reconciler, got %s", buffer.String())
+}
+
+func TestControllerTracesReconciles(t *testing.T) {
+	buffer := new(syncBuffer)
+	logger.SetOutputFormat(buffer, logger.JSONFormat)
+	defer logger.SetOutputFormat(new(bytes.Buffer), logger.ConsoleFormat)
+
+	exporter, restore := tracingtest.InMemory()
+	defer restore()
+
+	clientset := fake.NewClientset(newTestDeployment("default", "web"))
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+
+	var mu sync.Mutex
+	attempts := 0
+	done := make(chan struct{})
+	ctrl, err := New("test", factory.Apps().V1().Deployments().Informer(), ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+		_, child := tracing.Start(ctx, "fetch")
+		child.End()
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			return Result{}, errors.New("transient error")
+		}
+		logger.FromContext(ctx).Info().Msg("inside reconcile")
+		close(done)
+		return Result{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory.Start(ctx.Done())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := ctrl.Run(ctx, 1); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}()
+
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reconcile")
+	}
+	cancel()
+	<-stopped
+
+	var reconciles []tracetest.SpanStub
+	children := 0
+	for _, span := range exporter.GetSpans() {
+		switch span.Name {
+		case "Reconcile test":
+			reconciles = append(reconciles, span)
+		case "fetch":
+			children++
+		}
+	}
+	if len(reconciles) != 2 || children != 2 {
+		t.Fatalf("Expected 2 reconcile spans with a child each, got %d and %d", len(reconciles), children)
+	}
+
+	failed, succeeded := reconciles[0], reconciles[1]
+	if failed.Status.Code != codes.Error || len(failed.Events) == 0 {
+		t.Errorf("Expected the failed reconcile to record the error, got %+v", failed.Status)
+	}
+	expected := map[attribute.Key]string{"controller": "test", "key": "default/web", "result": "success"}
+	for _, attr := range succeeded.Attributes {
+		if want, ok := expected[attr.Key]; ok && attr.Value.AsString() != want {
+			t.Errorf("Expected attribute %s=%s, got %s", attr.Key, want, attr.Value.AsString())
+		}
+		delete(expected, attr.Key)
+	}
+	if len(expected) > 0 {
+		t.Errorf("Missing span attributes %v", expected)
+	}
+
+	traceID := `"trace_id":"` + succeeded.SpanContext.TraceID().String() + `"`
+	if !bytes.Contains(buffer.Bytes(), []byte(traceID)) {
+		t.Errorf("Expected reconcile log lines to carry %s, got %s", traceID, buffer.String())
+	}
+}
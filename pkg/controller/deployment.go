@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+
+	"k8s-controller/pkg/logger"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func init() {
+	Register("deployment", newDeploymentController)
+}
+
+// newDeploymentController wires the Deployment informer to a DeploymentReconciler
+func newDeploymentController(deps Dependencies) (*Controller, error) {
+	deployments := deps.Informers.Apps().V1().Deployments()
+	return New("deployment", deployments.Informer(), NewDeploymentReconciler(deployments.Lister()))
+}
+
+// DeploymentReconciler reconciles Deployments observed by the informer
+type DeploymentReconciler struct {
+	lister appslisters.DeploymentLister
+}
+
+// NewDeploymentReconciler creates a reconciler backed by the informer lister
+func NewDeploymentReconciler(lister appslisters.DeploymentLister) *DeploymentReconciler {
+	return &DeploymentReconciler{lister: lister}
+}
+
+// Reconcile logs the observed state of the Deployment identified by key
+func (r *DeploymentReconciler) Reconcile(ctx context.Context, key string) (Result, error) {
+	log := logger.FromContext(ctx)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return Result{}, err
+	}
+
+	deployment, err := r.lister.Deployments(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		log.Info().Msg("Deployment deleted")
+		return Result{}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	var replicas int32
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	log.Info().
+		Int32("replicas", replicas).
+		Int32("ready_replicas", deployment.Status.ReadyReplicas).
+		Msg("Deployment reconciled")
+
+	return Result{}, nil
+}
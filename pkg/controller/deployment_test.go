@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"k8s-controller/pkg/logger"
+
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDeploymentReconciler(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutput(buffer)
+
+	// Lister backed by a plain indexer, no informer needed
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(newTestDeployment("default", "web")); err != nil {
+		t.Fatalf("Failed to add deployment to indexer: %v", err)
+	}
+	reconciler := NewDeploymentReconciler(appslisters.NewDeploymentLister(indexer))
+
+	testCases := []struct {
+		name        string
+		key         string
+		expectError bool
+		expectLog   string
+	}{
+		{
+			name:      "Existing deployment",
+			key:       "default/web",
+			expectLog: "Deployment reconciled",
+		},
+		{
+			name:      "Deleted deployment",
+			key:       "default/gone",
+			expectLog: "Deployment deleted",
+		},
+		{
+			name:        "Invalid key",
+			key:         "a/b/c",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer.Reset()
+
+			_, err := reconciler.Reconcile(context.Background(), tc.key)
+			if tc.expectError && err == nil {
+				t.Error("Expected an error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tc.expectLog != "" && !strings.Contains(buffer.String(), tc.expectLog) {
+				t.Errorf("Expected log output to contain '%s', got: %s", tc.expectLog, buffer.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s-controller/api/v1alpha1"
+	"k8s-controller/pkg/logger"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/reference"
+)
+
+// eventComponent is the source component of every event
+const eventComponent = "k8s-controller"
+
+// Scheme resolves the kind of the typed objects events refer to. It knows
+// the built-in kinds and those of api/v1alpha1.
+var Scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(Scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(Scheme))
+}
+
+// EventRecorder emits Kubernetes Events on the objects being reconciled and
+// mirrors each one as a debug log line. A nil EventRecorder only logs.
+type EventRecorder struct {
+	broadcaster record.EventBroadcaster
+	recorder    record.EventRecorder
+}
+
+// NewEventRecorder creates a recorder that writes events through client.
+// Events are correlated by client-go before they are written: an event
+// repeated with the same reason and message increments the count of the
+// existing Event, more than 10 similar events within 10 minutes are
+// aggregated into one, and each object gets a burst of 25 events refilled
+// at one event every 5 minutes.
+func NewEventRecorder(client kubernetes.Interface) *EventRecorder {
+	return newEventRecorder(client, record.CorrelatorOptions{})
+}
+
+// newEventRecorder creates a recorder with explicit correlation settings;
+// zero fields take the client-go defaults
+func newEventRecorder(client kubernetes.Interface, options record.CorrelatorOptions) *EventRecorder {
+	broadcaster := record.NewBroadcaster(record.WithCorrelatorOptions(options))
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return &EventRecorder{
+		broadcaster: broadcaster,
+		recorder:    broadcaster.NewRecorder(Scheme, corev1.EventSource{Component: eventComponent}),
+	}
+}
+
+// Normal records an informational event about obj, such as a child object
+// being created
+func (r *EventRecorder) Normal(ctx context.Context, obj runtime.Object, reason, messageFormat string, args ...interface{}) {
+	r.event(ctx, obj, corev1.EventTypeNormal, reason, fmt.Sprintf(messageFormat, args...))
+}
+
+// Warning records an event about a problem with obj that users should look
+// at, such as a failed reconcile
+func (r *EventRecorder) Warning(ctx context.Context, obj runtime.Object, reason, messageFormat string, args ...interface{}) {
+	r.event(ctx, obj, corev1.EventTypeWarning, reason, fmt.Sprintf(messageFormat, args...))
+}
+
+// event logs the event at debug level through the logger of ctx, so the line
+// carries the controller, key and trace of the reconcile, then hands it to
+// the recorder. The line is logged before correlation, so it is emitted even
+// for events the recorder aggregates or drops.
+func (r *EventRecorder) event(ctx context.Context, obj runtime.Object, eventType, reason, message string) {
+	log := logger.FromContext(ctx)
+
+	ref, err := reference.GetReference(Scheme, obj)
+	if err != nil {
+		log.Error().Err(err).Str("reason", reason).Msg("Failed to reference event object")
+		return
+	}
+
+	log.Debug().
+		Str("event_type", eventType).
+		Str("reason", reason).
+		Str("object_kind", ref.Kind).
+		Str("object", objectName(ref)).
+		Str("message", message).
+		Msg("Event emitted")
+
+	if r != nil {
+		r.recorder.Event(obj, eventType, reason, message)
+	}
+}
+
+// objectName returns namespace/name, or name for cluster-scoped objects
+func objectName(ref *corev1.ObjectReference) string {
+	if ref.Namespace == "" {
+		return ref.Name
+	}
+	return ref.Namespace + "/" + ref.Name
+}
+
+// Shutdown stops writing events. Events still being correlated may be lost.
+func (r *EventRecorder) Shutdown() {
+	if r != nil {
+		r.broadcaster.Shutdown()
+	}
+}
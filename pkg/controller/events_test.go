@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-controller/api/v1alpha1"
+	"k8s-controller/pkg/logger"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+// waitForEvents polls the fake clientset until check accepts its events
+func waitForEvents(t *testing.T, clientset *fake.Clientset, check func([]corev1.Event) bool) []corev1.Event {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		list, err := clientset.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Failed to list events: %v", err)
+		}
+		if check(list.Items) {
+			return list.Items
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for events, got %+v", list.Items)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventRecorderDeduplicates(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutputFormat(buffer, logger.JSONFormat)
+	defer logger.SetOutputFormat(new(bytes.Buffer), logger.ConsoleFormat)
+	logger.SetLevel(logger.DebugLevel)
+	defer logger.SetLevel(logger.InfoLevel)
+
+	clientset := fake.NewClientset()
+	recorder := NewEventRecorder(clientset)
+	defer recorder.Shutdown()
+
+	deployment := newTestDeployment("default", "web")
+	for i := 0; i < 3; i++ {
+		recorder.Warning(context.Background(), deployment, "ScaleFailed", "Failed to scale to %d replicas", 3)
+	}
+
+	events := waitForEvents(t, clientset, func(events []corev1.Event) bool {
+		return len(events) == 1 && events[0].Count == 3
+	})
+	event := events[0]
+	if event.Type != corev1.EventTypeWarning || event.Reason != "ScaleFailed" || event.Message != "Failed to scale to 3 replicas" {
+		t.Errorf("Unexpected event %s %s %q", event.Type, event.Reason, event.Message)
+	}
+	if event.InvolvedObject.Kind != "Deployment" || event.InvolvedObject.Name != "web" || event.Namespace != "default" {
+		t.Errorf("Expected the event to refer to the deployment, got %+v", event.InvolvedObject)
+	}
+	if event.Source.Component != eventComponent {
+		t.Errorf("Expected source component %s, got %s", eventComponent, event.Source.Component)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected every event to be logged, got %d lines", len(lines))
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Failed to parse log line: %v", err)
+	}
+	expected := map[string]interface{}{
+		"level":       "debug",
+		"msg":         "Event emitted",
+		"event_type":  "Warning",
+		"reason":      "ScaleFailed",
+		"object_kind": "Deployment",
+		"object":      "default/web",
+		"message":     "Failed to scale to 3 replicas",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("Expected %s=%v in the log line, got %v", key, value, entry[key])
+		}
+	}
+}
+
+func TestEventRecorderRateLimits(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	clientset := fake.NewClientset()
+	recorder := newEventRecorder(clientset, record.CorrelatorOptions{BurstSize: 2, QPS: 1e-6})
+	defer recorder.Shutdown()
+
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "uid-web"}}
+	for _, reason := range []string{"First", "Second", "Third", "Fourth"} {
+		recorder.Normal(context.Background(), app, reason, "%s event", reason)
+	}
+
+	waitForEvents(t, clientset, func(events []corev1.Event) bool {
+		return len(events) >= 2
+	})
+	// Give dropped events a chance to show up if rate limiting failed
+	time.Sleep(100 * time.Millisecond)
+	events := waitForEvents(t, clientset, func([]corev1.Event) bool { return true })
+	if len(events) != 2 {
+		t.Errorf("Expected the burst of 2 events to be written, got %d", len(events))
+	}
+	for _, event := range events {
+		if event.InvolvedObject.Kind != "Application" || event.InvolvedObject.APIVersion != v1alpha1.GroupVersion.String() {
+			t.Errorf("Expected events to refer to the application, got %+v", event.InvolvedObject)
+		}
+	}
+}
+
+func TestNilEventRecorderLogs(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutputFormat(buffer, logger.JSONFormat)
+	defer logger.SetOutputFormat(new(bytes.Buffer), logger.ConsoleFormat)
+
+	var recorder *EventRecorder
+	recorder.Normal(context.Background(), newTestDeployment("default", "web"), "Created", "Created deployment")
+	if buffer.Len() != 0 {
+		t.Errorf("Expected events not to be logged at info level, got %s", buffer.String())
+	}
+
+	logger.SetLevel(logger.DebugLevel)
+	defer logger.SetLevel(logger.InfoLevel)
+	recorder.Normal(context.Background(), newTestDeployment("default", "web"), "Created", "Created deployment")
+	recorder.Shutdown()
+
+	if !strings.Contains(buffer.String(), `"reason":"Created"`) {
+		t.Errorf("Expected a nil recorder to still log the event, got %s", buffer.String())
+	}
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/metrics"
+	"k8s-controller/pkg/tracing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Backoff between cleanup attempts of a single object
+const (
+	finalizerBaseDelay = time.Second
+	finalizerMaxDelay  = 5 * time.Minute
+)
+
+// UpdateFunc writes obj, with the signature of the Update methods of typed
+// clients
+type UpdateFunc[T metav1.Object] func(ctx context.Context, obj T, opts metav1.UpdateOptions) (T, error)
+
+// CleanupFunc removes the external state of an object that is being deleted.
+// It is called again until it succeeds, so it must be idempotent.
+type CleanupFunc[T metav1.Object] func(ctx context.Context, obj T) error
+
+// Finalizer keeps a finalizer on the objects of a controller so their
+// external state is cleaned up before they are deleted
+type Finalizer[T metav1.Object] struct {
+	controller string
+	name       string
+	cleanup    CleanupFunc[T]
+	update     UpdateFunc[T]
+
+	// backoff spaces out cleanup attempts per object. Failures are retried
+	// with RequeueAfter instead of an error, so the workqueue does not drop
+	// the key after maxRetries while the object cannot be deleted.
+	backoff workqueue.TypedRateLimiter[string]
+
+	// pending holds the deletion timestamps of objects whose cleanup failed
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+// NewFinalizer creates a finalizer called name, e.g.
+// "k8s-controller.io/cleanup", for the objects of controller. update
+// persists the finalizer list, usually the Update method of a typed client.
+func NewFinalizer[T metav1.Object](controller, name string, cleanup CleanupFunc[T], update UpdateFunc[T]) *Finalizer[T] {
+	return &Finalizer[T]{
+		controller: controller,
+		name:       name,
+		cleanup:    cleanup,
+		update:     update,
+		backoff:    workqueue.NewTypedItemExponentialFailureRateLimiter[string](finalizerBaseDelay, finalizerMaxDelay),
+		pending:    make(map[string]time.Time),
+	}
+}
+
+// Finalize adds the finalizer to obj while it exists, and runs the cleanup
+// once obj is being deleted, removing the finalizer when the cleanup
+// succeeds. It returns obj as stored by the API server, and whether obj is
+// being deleted, in which case the reconciler should return the result and
+// error without reconciling obj any further. Adding or removing the finalizer
+// modifies obj in place, so objects taken from a lister must be passed as a
+// DeepCopy():
+//
+//	app, result, done, err := r.finalizer.Finalize(ctx, cached.DeepCopy())
+//	if done || err != nil {
+//		return result, err
+//	}
+func (f *Finalizer[T]) Finalize(ctx context.Context, obj T) (T, Result, bool, error) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return obj, Result{}, false, err
+	}
+	log := logger.FromContext(ctx).With().Str("finalizer", f.name).Logger()
+
+	if obj.GetDeletionTimestamp() == nil {
+		if !AddFinalizer(obj, f.name) {
+			return obj, Result{}, false, nil
+		}
+		updated, err := f.update(ctx, obj, metav1.UpdateOptions{})
+		if err != nil {
+			return obj, Result{}, false, fmt.Errorf("failed to add finalizer %s: %w", f.name, err)
+		}
+		log.Debug().Msg("Finalizer added")
+		return updated, Result{}, false, nil
+	}
+
+	if !HasFinalizer(obj, f.name) {
+		f.Forget(key)
+		return obj, Result{}, true, nil
+	}
+
+	if err := f.cleanup(ctx, obj); err != nil {
+		metrics.ObserveFinalizerCleanup(f.controller, f.name, "error")
+		f.setPending(key, obj.GetDeletionTimestamp().Time)
+
+		delay := f.backoff.When(key)
+		tracing.SpanFromContext(ctx).RecordError(err)
+		log.Warn().Err(err).Dur("retry_after", delay).Msg("Finalizer cleanup failed, retrying")
+		return obj, Result{RequeueAfter: delay}, true, nil
+	}
+	metrics.ObserveFinalizerCleanup(f.controller, f.name, "success")
+
+	RemoveFinalizer(obj, f.name)
+	updated, err := f.update(ctx, obj, metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) {
+		// The last finalizer was removed concurrently and obj is gone
+		f.Forget(key)
+		return obj, Result{}, true, nil
+	}
+	if err != nil {
+		return obj, Result{}, true, fmt.Errorf("failed to remove finalizer %s: %w", f.name, err)
+	}
+	f.Forget(key)
+	log.Info().Msg("Finalizer cleanup completed")
+	return updated, Result{}, true, nil
+}
+
+// Forget drops the retry state of the object with key, for reconcilers that
+// find the object already gone
+func (f *Finalizer[T]) Forget(key string) {
+	f.backoff.Forget(key)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.pending[key]; ok {
+		delete(f.pending, key)
+		f.reportPending()
+	}
+}
+
+func (f *Finalizer[T]) setPending(key string, deleted time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending[key] = deleted
+	f.reportPending()
+}
+
+// reportPending updates the stuck finalizer metrics; f.mu must be held
+func (f *Finalizer[T]) reportPending() {
+	var oldest time.Time
+	for _, deleted := range f.pending {
+		if oldest.IsZero() || deleted.Before(oldest) {
+			oldest = deleted
+		}
+	}
+	metrics.SetFinalizerPending(f.controller, f.name, len(f.pending), oldest)
+}
+
+// HasFinalizer reports whether obj carries the finalizer name
+func HasFinalizer(obj metav1.Object, name string) bool {
+	return slices.Contains(obj.GetFinalizers(), name)
+}
+
+// AddFinalizer adds the finalizer name to obj and reports whether it was
+// missing
+func AddFinalizer(obj metav1.Object, name string) bool {
+	if HasFinalizer(obj, name) {
+		return false
+	}
+	obj.SetFinalizers(append(slices.Clone(obj.GetFinalizers()), name))
+	return true
+}
+
+// RemoveFinalizer removes the finalizer name from obj and reports whether it
+// was present
+func RemoveFinalizer(obj metav1.Object, name string) bool {
+	finalizers := obj.GetFinalizers()
+	kept := slices.DeleteFunc(slices.Clone(finalizers), func(f string) bool { return f == name })
+	if len(kept) == len(finalizers) {
+		return false
+	}
+	obj.SetFinalizers(kept)
+	return true
+}
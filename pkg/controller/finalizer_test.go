@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/metrics"
+
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testFinalizer = "k8s-controller.io/test-cleanup"
+
+func TestFinalizerAddsFinalizer(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	deployment := newTestDeployment("default", "web")
+	clientset := fake.NewClientset(deployment)
+	cleanups := 0
+	finalizer := NewFinalizer("test", testFinalizer, func(ctx context.Context, d *appsv1.Deployment) error {
+		cleanups++
+		return nil
+	}, clientset.AppsV1().Deployments("default").Update)
+
+	updated, result, done, err := finalizer.Finalize(context.Background(), deployment.DeepCopy())
+	if err != nil || done || result != (Result{}) {
+		t.Fatalf("Expected a live object to be reconciled further, got result=%+v done=%v err=%v", result, done, err)
+	}
+	if !HasFinalizer(updated, testFinalizer) {
+		t.Errorf("Expected the finalizer to be added, got %v", updated.Finalizers)
+	}
+	stored, _ := clientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if !HasFinalizer(stored, testFinalizer) {
+		t.Errorf("Expected the finalizer to be stored, got %v", stored.Finalizers)
+	}
+
+	actions := len(clientset.Actions())
+	if _, _, _, err := finalizer.Finalize(context.Background(), updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(clientset.Actions()) != actions {
+		t.Error("Expected no update when the finalizer is already present")
+	}
+	if cleanups != 0 {
+		t.Errorf("Expected no cleanup for a live object, got %d", cleanups)
+	}
+}
+
+func TestFinalizerRetriesCleanup(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	deleted := metav1.NewTime(time.Unix(1700000000, 0))
+	deployment := newTestDeployment("default", "retry")
+	deployment.Finalizers = []string{"other.io/keep", testFinalizer}
+	deployment.DeletionTimestamp = &deleted
+	clientset := fake.NewClientset(deployment)
+
+	failures := 2
+	finalizer := NewFinalizer("test", testFinalizer, func(ctx context.Context, d *appsv1.Deployment) error {
+		if failures > 0 {
+			failures--
+			return errors.New("external API unavailable")
+		}
+		return nil
+	}, clientset.AppsV1().Deployments("default").Update)
+
+	for _, expected := range []time.Duration{finalizerBaseDelay, 2 * finalizerBaseDelay} {
+		_, result, done, err := finalizer.Finalize(context.Background(), deployment.DeepCopy())
+		if err != nil || !done {
+			t.Fatalf("Expected a failed cleanup to stop the reconcile without error, got done=%v err=%v", done, err)
+		}
+		if result.RequeueAfter != expected {
+			t.Errorf("Expected a retry after %s, got %s", expected, result.RequeueAfter)
+		}
+	}
+
+	body := scrapeMetrics()
+	for _, metric := range []string{
+		`k8s_controller_controller_finalizer_pending_objects{controller="test",finalizer="k8s-controller.io/test-cleanup"} 1`,
+		`k8s_controller_controller_finalizer_oldest_pending_deletion_timestamp_seconds{controller="test",finalizer="k8s-controller.io/test-cleanup"} 1.7e+09`,
+	} {
+		if !strings.Contains(body, metric) {
+			t.Errorf("Expected metrics output to contain %q", metric)
+		}
+	}
+	if len(clientset.Actions()) != 0 {
+		t.Errorf("Expected the finalizer to stay while cleanup fails, got %v", clientset.Actions())
+	}
+
+	updated, result, done, err := finalizer.Finalize(context.Background(), deployment.DeepCopy())
+	if err != nil || !done || result != (Result{}) {
+		t.Fatalf("Expected the cleanup to complete, got result=%+v done=%v err=%v", result, done, err)
+	}
+	if !reflect.DeepEqual(updated.Finalizers, []string{"other.io/keep"}) {
+		t.Errorf("Expected only the finalizer to be removed, got %v", updated.Finalizers)
+	}
+	if !strings.Contains(scrapeMetrics(), `k8s_controller_controller_finalizer_pending_objects{controller="test",finalizer="k8s-controller.io/test-cleanup"} 0`) {
+		t.Error("Expected no pending objects after the cleanup succeeded")
+	}
+	if got := finalizer.backoff.NumRequeues("default/retry"); got != 0 {
+		t.Errorf("Expected the backoff to be reset, got %d requeues", got)
+	}
+}
+
+func TestFinalizerSkipsForeignDeletion(t *testing.T) {
+	deleted := metav1.Now()
+	deployment := newTestDeployment("default", "foreign")
+	deployment.Finalizers = []string{"other.io/keep"}
+	deployment.DeletionTimestamp = &deleted
+	clientset := fake.NewClientset(deployment)
+
+	finalizer := NewFinalizer("test", testFinalizer, func(ctx context.Context, d *appsv1.Deployment) error {
+		t.Error("Expected no cleanup without the finalizer")
+		return nil
+	}, clientset.AppsV1().Deployments("default").Update)
+
+	_, _, done, err := finalizer.Finalize(context.Background(), deployment)
+	if err != nil || !done {
+		t.Errorf("Expected a deleted object to stop the reconcile, got done=%v err=%v", done, err)
+	}
+	if len(clientset.Actions()) != 0 {
+		t.Errorf("Expected no API calls, got %v", clientset.Actions())
+	}
+}
+
+func TestFinalizerHelpers(t *testing.T) {
+	finalizers := []string{"a.io/one", "b.io/two"}
+	deployment := newTestDeployment("default", "web")
+	deployment.Finalizers = finalizers[:1]
+
+	if !AddFinalizer(deployment, "c.io/three") || AddFinalizer(deployment, "c.io/three") {
+		t.Error("Expected AddFinalizer to report only the first add")
+	}
+	if finalizers[1] != "b.io/two" {
+		t.Error("Expected AddFinalizer not to write to the shared backing array")
+	}
+	if !RemoveFinalizer(deployment, "a.io/one") || RemoveFinalizer(deployment, "a.io/one") {
+		t.Error("Expected RemoveFinalizer to report only the first removal")
+	}
+	if !reflect.DeepEqual(deployment.Finalizers, []string{"c.io/three"}) {
+		t.Errorf("Unexpected finalizers %v", deployment.Finalizers)
+	}
+	if finalizers[0] != "a.io/one" {
+		t.Error("Expected RemoveFinalizer not to modify the original slice")
+	}
+}
+
+// scrapeMetrics returns the Prometheus text output of the metrics registry
+func scrapeMetrics() string {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/metrics")
+	metrics.Handler()(ctx)
+	return string(ctx.Response.Body())
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s-controller/pkg/logger"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// OperationResult tells what Ensure did to a child object
+type OperationResult string
+
+// Results of Ensure
+const (
+	OperationCreated   OperationResult = "created"
+	OperationUpdated   OperationResult = "updated"
+	OperationUnchanged OperationResult = "unchanged"
+)
+
+// Object is a Kubernetes API object, such as *appsv1.Deployment
+type Object interface {
+	metav1.Object
+	runtime.Object
+}
+
+// ChildClient reads and writes child objects in one namespace; typed
+// clients such as clientset.AppsV1().Deployments(namespace) implement it
+type ChildClient[T Object] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Create(ctx context.Context, obj T, opts metav1.CreateOptions) (T, error)
+	Update(ctx context.Context, obj T, opts metav1.UpdateOptions) (T, error)
+}
+
+// Ensure makes the child named like child match its desired state and be
+// controlled by owner, whose kind is ownerKind. mutate sets the desired
+// fields on child when it does not exist yet, or on the stored object, so
+// fields defaulted by the API server are kept. The child is only updated
+// when mutate changed something, which corrects manual edits without
+// writing on every reconcile. A child controlled by another object is left
+// alone and reported as an error.
+//
+//	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: app.Namespace, Name: app.Name}}
+//	_, _, err := controller.Ensure(ctx, client.AppsV1().Deployments(app.Namespace), app, applicationKind, deployment,
+//		func(d *appsv1.Deployment) error {
+//			d.Spec.Replicas = app.Spec.Replicas
+//			return nil
+//		})
+func Ensure[T Object](ctx context.Context, client ChildClient[T], owner metav1.Object, ownerKind schema.GroupVersionKind, child T, mutate func(T) error) (T, OperationResult, error) {
+	kind := kindOf(child)
+	log := logger.FromContext(ctx).With().
+		Str("child_kind", kind).
+		Str("child", child.GetName()).
+		Logger()
+
+	existing, err := client.Get(ctx, child.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if err := mutate(child); err != nil {
+			return child, "", err
+		}
+		if err := setControllerReference(owner, ownerKind, child); err != nil {
+			return child, "", err
+		}
+		created, err := client.Create(ctx, child, metav1.CreateOptions{})
+		if err != nil {
+			return child, "", fmt.Errorf("failed to create %s %s: %w", kind, child.GetName(), err)
+		}
+		log.Info().Msg("Child created")
+		return created, OperationCreated, nil
+	}
+	if err != nil {
+		return child, "", fmt.Errorf("failed to get %s %s: %w", kind, child.GetName(), err)
+	}
+
+	// Keep the stored object to tell whether mutate changed anything
+	before := existing
+	existing = existing.DeepCopyObject().(T)
+	if err := mutate(existing); err != nil {
+		return existing, "", err
+	}
+	if err := setControllerReference(owner, ownerKind, existing); err != nil {
+		return existing, "", err
+	}
+	if equality.Semantic.DeepEqual(before, existing) {
+		return existing, OperationUnchanged, nil
+	}
+
+	updated, err := client.Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		return existing, "", fmt.Errorf("failed to update %s %s: %w", kind, child.GetName(), err)
+	}
+	log.Info().Msg("Child updated")
+	return updated, OperationUpdated, nil
+}
+
+// setControllerReference makes owner the controller of child, unless
+// another object already controls it
+func setControllerReference(owner metav1.Object, ownerKind schema.GroupVersionKind, child metav1.Object) error {
+	ref := metav1.NewControllerRef(owner, ownerKind)
+	if current := metav1.GetControllerOf(child); current != nil {
+		if current.UID != ref.UID {
+			return fmt.Errorf("%s/%s is already controlled by %s %s", child.GetNamespace(), child.GetName(), current.Kind, current.Name)
+		}
+		return nil
+	}
+	child.SetOwnerReferences(append(child.GetOwnerReferences(), *ref))
+	return nil
+}
+
+// kindOf returns the Go type name of obj, which matches its kind for typed
+// objects whose TypeMeta is not filled in
+func kindOf(obj runtime.Object) string {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// Owns watches the children in informer and enqueues their controlling
+// object whenever a child of ownerKind is added, changed or deleted, so the
+// next reconcile restores children that were edited or removed. Owners must
+// live in the namespace of their children, or be cluster-scoped along with
+// them. Owns must be called before Run, which then also waits for informer
+// to sync.
+func (c *Controller) Owns(informer cache.SharedIndexInformer, ownerKind schema.GroupKind) error {
+	enqueueOwner := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		child, ok := obj.(metav1.Object)
+		if !ok {
+			return
+		}
+		ref := metav1.GetControllerOf(child)
+		if ref == nil || ref.Kind != ownerKind.Kind {
+			return
+		}
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err != nil || gv.Group != ownerKind.Group {
+			return
+		}
+
+		key := ref.Name
+		if child.GetNamespace() != "" {
+			key = child.GetNamespace() + "/" + ref.Name
+		}
+		c.log.Debug().Str("child", child.GetName()).Str("key", key).Msg("Child changed, enqueuing owner")
+		c.queue.Add(key)
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueueOwner,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldChild, oldOK := oldObj.(metav1.Object)
+			newChild, newOK := newObj.(metav1.Object)
+			if oldOK && newOK && oldChild.GetResourceVersion() == newChild.GetResourceVersion() {
+				// Periodic resyncs change nothing
+				return
+			}
+			// A child that changed owner is reported to both owners
+			enqueueOwner(oldObj)
+			enqueueOwner(newObj)
+		},
+		DeleteFunc: enqueueOwner,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add child event handler for %s controller: %w", c.name, err)
+	}
+
+	c.synced = append(c.synced, informer.HasSynced)
+	return nil
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-controller/pkg/logger"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+var configMapKind = corev1.SchemeGroupVersion.WithKind("ConfigMap")
+
+func newTestOwner(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      name,
+		UID:       types.UID("uid-" + name),
+	}}
+}
+
+func TestEnsure(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	owner := newTestOwner("web")
+	clientset := fake.NewClientset(owner)
+	deployments := clientset.AppsV1().Deployments("default")
+	ensure := func(replicas int32) (*appsv1.Deployment, OperationResult, error) {
+		child := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+		return Ensure(context.Background(), deployments, owner, configMapKind, child, func(d *appsv1.Deployment) error {
+			d.Spec.Replicas = ptr.To(replicas)
+			return nil
+		})
+	}
+
+	created, result, err := ensure(2)
+	if err != nil || result != OperationCreated {
+		t.Fatalf("Expected the child to be created, got %s (%v)", result, err)
+	}
+	ref := metav1.GetControllerOf(created)
+	if ref == nil || ref.UID != owner.UID || ref.Kind != "ConfigMap" || !*ref.BlockOwnerDeletion {
+		t.Errorf("Expected a controller reference to the owner, got %+v", created.OwnerReferences)
+	}
+
+	actions := len(clientset.Actions())
+	if _, result, err := ensure(2); err != nil || result != OperationUnchanged {
+		t.Errorf("Expected an unchanged child, got %s (%v)", result, err)
+	}
+	for _, action := range clientset.Actions()[actions:] {
+		if action.GetVerb() != "get" {
+			t.Errorf("Expected no writes for an unchanged child, got %s", action.GetVerb())
+		}
+	}
+
+	// A manual edit is reverted, keeping fields mutate does not manage
+	drifted, _ := deployments.Get(context.Background(), "web", metav1.GetOptions{})
+	drifted.Spec.Replicas = ptr.To[int32](10)
+	drifted.Labels = map[string]string{"edited": "by-hand"}
+	if _, err := deployments.Update(context.Background(), drifted, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to edit deployment: %v", err)
+	}
+	updated, result, err := ensure(2)
+	if err != nil || result != OperationUpdated {
+		t.Fatalf("Expected the drift to be corrected, got %s (%v)", result, err)
+	}
+	if *updated.Spec.Replicas != 2 || updated.Labels["edited"] != "by-hand" {
+		t.Errorf("Expected replicas to be restored and labels kept, got %d %v", *updated.Spec.Replicas, updated.Labels)
+	}
+}
+
+func TestEnsureRefusesChildOfAnotherOwner(t *testing.T) {
+	other := newTestOwner("other")
+	child := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "default",
+		Name:            "web",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(other, configMapKind)},
+	}}
+	clientset := fake.NewClientset(child)
+
+	desired := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	_, _, err := Ensure(context.Background(), clientset.AppsV1().Deployments("default"), newTestOwner("web"), configMapKind, desired,
+		func(d *appsv1.Deployment) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "already controlled by ConfigMap other") {
+		t.Errorf("Expected an error naming the other owner, got %v", err)
+	}
+}
+
+func TestControllerOwns(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	clientset := fake.NewClientset(newTestOwner("web"))
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+
+	keys := make(chan string, 10)
+	ctrl, err := New("test", factory.Core().V1().ConfigMaps().Informer(), ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+		keys <- key
+		return Result{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+	if err := ctrl.Owns(factory.Apps().V1().Deployments().Informer(), configMapKind.GroupKind()); err != nil {
+		t.Fatalf("Failed to watch children: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory.Start(ctx.Done())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := ctrl.Run(ctx, 1); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	expectKey := func(event string) {
+		t.Helper()
+		select {
+		case key := <-keys:
+			if key != "default/web" {
+				t.Errorf("Expected the owner to be reconciled after %s, got %s", event, key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for the owner after %s", event)
+		}
+	}
+	expectKey("the initial list")
+
+	// Children of other kinds do not enqueue anything
+	deployments := clientset.AppsV1().Deployments("default")
+	foreign := newTestDeployment("default", "foreign")
+	foreign.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(newTestOwner("other"), appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))}
+	if _, err := deployments.Create(ctx, foreign, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create deployment: %v", err)
+	}
+
+	child := newTestDeployment("default", "web-child")
+	child.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(newTestOwner("web"), configMapKind)}
+	child, err = deployments.Create(ctx, child, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create deployment: %v", err)
+	}
+	expectKey("a child was created")
+
+	child.Spec.Replicas = ptr.To[int32](3)
+	child.ResourceVersion = "2"
+	if _, err := deployments.Update(ctx, child, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update deployment: %v", err)
+	}
+	expectKey("a child was modified")
+
+	if err := deployments.Delete(ctx, "web-child", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete deployment: %v", err)
+	}
+	expectKey("a child was deleted")
+
+	if !ctrl.HasSynced() {
+		t.Error("Expected the controller to report the child informer as synced")
+	}
+}
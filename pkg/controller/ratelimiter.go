@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// RateLimits configures how fast failed keys are retried by every controller
+// workqueue: per-key exponential backoff from BaseDelay up to MaxDelay,
+// combined with an overall token bucket of QPS and Burst
+type RateLimits struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	QPS       float64
+	Burst     int
+}
+
+// DefaultRateLimits matches workqueue.DefaultTypedControllerRateLimiter
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		BaseDelay: 5 * time.Millisecond,
+		MaxDelay:  1000 * time.Second,
+		QPS:       10,
+		Burst:     100,
+	}
+}
+
+var rateLimits atomic.Pointer[RateLimits]
+
+func init() {
+	SetRateLimits(DefaultRateLimits())
+}
+
+// SetRateLimits changes the rate limits of every controller, including
+// running ones. Backoff of keys that are currently failing restarts from
+// BaseDelay.
+func SetRateLimits(limits RateLimits) {
+	rateLimits.Store(&limits)
+}
+
+// CurrentRateLimits returns the rate limits in effect
+func CurrentRateLimits() RateLimits {
+	return *rateLimits.Load()
+}
+
+// reloadableRateLimiter rebuilds its underlying rate limiter whenever the
+// package rate limits change
+type reloadableRateLimiter struct {
+	mu      sync.Mutex
+	limits  RateLimits
+	limiter workqueue.TypedRateLimiter[string]
+}
+
+func newReloadableRateLimiter() *reloadableRateLimiter {
+	return &reloadableRateLimiter{}
+}
+
+func (r *reloadableRateLimiter) current() workqueue.TypedRateLimiter[string] {
+	limits := CurrentRateLimits()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.limiter == nil || limits != r.limits {
+		r.limits = limits
+		r.limiter = workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[string](limits.BaseDelay, limits.MaxDelay),
+			&workqueue.TypedBucketRateLimiter[string]{Limiter: rate.NewLimiter(rate.Limit(limits.QPS), limits.Burst)},
+		)
+	}
+	return r.limiter
+}
+
+// When returns how long to wait before adding the key back
+func (r *reloadableRateLimiter) When(key string) time.Duration {
+	return r.current().When(key)
+}
+
+// Forget stops tracking failures of the key
+func (r *reloadableRateLimiter) Forget(key string) {
+	r.current().Forget(key)
+}
+
+// NumRequeues returns how many times the key has failed
+func (r *reloadableRateLimiter) NumRequeues(key string) int {
+	return r.current().NumRequeues(key)
+}
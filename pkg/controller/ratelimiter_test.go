@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReloadableRateLimiter(t *testing.T) {
+	defer SetRateLimits(DefaultRateLimits())
+
+	limiter := newReloadableRateLimiter()
+	if d := limiter.When("default/a"); d != 5*time.Millisecond {
+		t.Errorf("Expected first backoff to be 5ms, got %s", d)
+	}
+	if d := limiter.When("default/a"); d != 10*time.Millisecond {
+		t.Errorf("Expected second backoff to be 10ms, got %s", d)
+	}
+	if n := limiter.NumRequeues("default/a"); n != 2 {
+		t.Errorf("Expected 2 requeues, got %d", n)
+	}
+
+	// New limits apply to the existing limiter and restart the backoff
+	SetRateLimits(RateLimits{BaseDelay: time.Second, MaxDelay: 2 * time.Second, QPS: 100, Burst: 10})
+	if d := limiter.When("default/a"); d != time.Second {
+		t.Errorf("Expected backoff to restart at 1s, got %s", d)
+	}
+	limiter.When("default/a")
+	if d := limiter.When("default/a"); d != 2*time.Second {
+		t.Errorf("Expected backoff to be capped at 2s, got %s", d)
+	}
+
+	limiter.Forget("default/a")
+	if n := limiter.NumRequeues("default/a"); n != 0 {
+		t.Errorf("Expected 0 requeues after Forget, got %d", n)
+	}
+}
@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Dependencies are the shared resources handed to every controller factory
+type Dependencies struct {
+	Client    kubernetes.Interface
+	Informers informers.SharedInformerFactory
+	Events    *EventRecorder
+}
+
+// Factory builds a controller from the shared dependencies
+type Factory func(deps Dependencies) (*Controller, error)
+
+// Options select which registered controllers run and how many workers each gets
+type Options struct {
+	// Enabled lists controller names to run. "*" enables every registered
+	// controller and "-name" disables a single one.
+	Enabled []string
+	// Workers is the default number of workers per controller
+	Workers int
+	// ControllerWorkers overrides Workers for individual controllers
+	ControllerWorkers map[string]int
+}
+
+// WorkersFor returns the worker count for the named controller
+func (o Options) WorkersFor(name string) int {
+	if workers, ok := o.ControllerWorkers[name]; ok && workers > 0 {
+		return workers
+	}
+	return o.Workers
+}
+
+// IsEnabled reports whether the named controller is selected by the Enabled list
+func (o Options) IsEnabled(name string) bool {
+	enabled := false
+	for _, selector := range o.Enabled {
+		switch selector {
+		case name:
+			enabled = true
+		case "-" + name:
+			return false
+		case "*":
+			enabled = true
+		}
+	}
+	return enabled
+}
+
+// Registry holds named controller factories
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+
+	// running holds the controllers started by Start
+	runningMu sync.RWMutex
+	running   []*Controller
+}
+
+// DefaultRegistry is the registry used by Register and the serve command
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds a controller factory to the DefaultRegistry. It is meant to be
+// called from init functions and panics if the name is already taken.
+func Register(name string, factory Factory) {
+	if err := DefaultRegistry.Register(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds a controller factory under the given name
+func (r *Registry) Register(name string, factory Factory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if name == "" || name == "*" || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("invalid controller name %q", name)
+	}
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("controller %q is already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Names returns the sorted names of all registered controllers
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.namesLocked()
+}
+
+// Build creates every controller enabled by the options. Unknown names in
+// the options are reported as an error.
+func (r *Registry) Build(deps Dependencies, opts Options) ([]*Controller, error) {
+	if err := r.validate(opts); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var controllers []*Controller
+	for _, name := range r.namesLocked() {
+		if !opts.IsEnabled(name) {
+			componentLogger(name).Info().Msg("Controller disabled")
+			continue
+		}
+		ctrl, err := r.factories[name](deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s controller: %w", name, err)
+		}
+		controllers = append(controllers, ctrl)
+	}
+
+	return controllers, nil
+}
+
+// Start builds the enabled controllers, starts the shared informers and runs
+// every controller with its worker count. It blocks until the context is
+// cancelled and returns the first controller error.
+func (r *Registry) Start(ctx context.Context, deps Dependencies, opts Options) error {
+	controllers, err := r.Build(deps, opts)
+	if err != nil {
+		return err
+	}
+	if len(controllers) == 0 {
+		return fmt.Errorf("no controllers enabled (registered: %s)", strings.Join(r.Names(), ", "))
+	}
+
+	r.setRunning(controllers)
+	defer r.setRunning(nil)
+
+	deps.Informers.Start(ctx.Done())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(controllers))
+	for _, ctrl := range controllers {
+		wg.Add(1)
+		go func(ctrl *Controller) {
+			defer wg.Done()
+			if err := ctrl.Run(ctx, opts.WorkersFor(ctrl.Name())); err != nil {
+				errs <- err
+			}
+		}(ctrl)
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// CheckInformersSynced returns an error naming the running controllers whose
+// informer caches have not synced yet. It succeeds while no controllers run,
+// e.g. on a replica waiting for leadership.
+func (r *Registry) CheckInformersSynced(ctx context.Context) error {
+	var pending []string
+	for _, ctrl := range r.runningControllers() {
+		if !ctrl.HasSynced() {
+			pending = append(pending, ctrl.Name())
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("informer caches not synced for controllers: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// CheckWorkqueues returns an error if any running controller has a reconcile
+// in flight for longer than threshold
+func (r *Registry) CheckWorkqueues(threshold time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for _, ctrl := range r.runningControllers() {
+			if err := ctrl.CheckStuck(threshold); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (r *Registry) setRunning(controllers []*Controller) {
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	r.running = controllers
+}
+
+func (r *Registry) runningControllers() []*Controller {
+	r.runningMu.RLock()
+	defer r.runningMu.RUnlock()
+	return r.running
+}
+
+// validate rejects selectors and worker overrides for unregistered controllers
+func (r *Registry) validate(opts Options) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, selector := range opts.Enabled {
+		name := strings.TrimPrefix(selector, "-")
+		if name == "*" {
+			continue
+		}
+		if _, ok := r.factories[name]; !ok {
+			return fmt.Errorf("unknown controller %q (registered: %s)", name, strings.Join(r.namesLocked(), ", "))
+		}
+	}
+	for name := range opts.ControllerWorkers {
+		if _, ok := r.factories[name]; !ok {
+			return fmt.Errorf("worker count set for unknown controller %q", name)
+		}
+	}
+	return nil
+}
+
+// namesLocked returns sorted names; the caller must hold the lock
+func (r *Registry) namesLocked() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
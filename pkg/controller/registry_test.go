@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s-controller/pkg/logger"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+	noop := func(deps Dependencies) (*Controller, error) { return nil, nil }
+
+	if err := registry.Register("b", noop); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := registry.Register("a", noop); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Duplicate and reserved names are rejected
+	for _, name := range []string{"a", "", "*", "-c"} {
+		if err := registry.Register(name, noop); err == nil {
+			t.Errorf("Expected an error registering %q", name)
+		}
+	}
+
+	if names := registry.Names(); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("Expected names [a b], got %v", names)
+	}
+}
+
+func TestDeploymentControllerRegistered(t *testing.T) {
+	found := false
+	for _, name := range DefaultRegistry.Names() {
+		if name == "deployment" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the deployment controller to be registered in DefaultRegistry")
+	}
+}
+
+func TestOptionsIsEnabled(t *testing.T) {
+	testCases := []struct {
+		name     string
+		enabled  []string
+		expected bool
+	}{
+		{name: "Wildcard", enabled: []string{"*"}, expected: true},
+		{name: "Explicit", enabled: []string{"foo"}, expected: true},
+		{name: "Not listed", enabled: []string{"bar"}, expected: false},
+		{name: "Wildcard with exclusion", enabled: []string{"*", "-foo"}, expected: false},
+		{name: "Exclusion wins over explicit", enabled: []string{"foo", "-foo"}, expected: false},
+		{name: "Empty", enabled: nil, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := Options{Enabled: tc.enabled}
+			if got := opts.IsEnabled("foo"); got != tc.expected {
+				t.Errorf("Expected IsEnabled to be %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestOptionsWorkersFor(t *testing.T) {
+	opts := Options{Workers: 2, ControllerWorkers: map[string]int{"foo": 5}}
+
+	if workers := opts.WorkersFor("foo"); workers != 5 {
+		t.Errorf("Expected 5 workers for foo, got %d", workers)
+	}
+	if workers := opts.WorkersFor("bar"); workers != 2 {
+		t.Errorf("Expected 2 workers for bar, got %d", workers)
+	}
+}
+
+func TestRegistryBuildRejectsUnknownControllers(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register("foo", func(deps Dependencies) (*Controller, error) { return nil, nil }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := registry.Build(Dependencies{}, Options{Enabled: []string{"bar"}}); err == nil {
+		t.Error("Expected an error for an unknown controller selector")
+	}
+	if _, err := registry.Build(Dependencies{}, Options{Enabled: []string{"*"}, ControllerWorkers: map[string]int{"bar": 1}}); err == nil {
+		t.Error("Expected an error for a worker override of an unknown controller")
+	}
+}
+
+func TestRegistryStart(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	clientset := fake.NewClientset(newTestDeployment("default", "web"))
+	deps := Dependencies{
+		Client:    clientset,
+		Informers: informers.NewSharedInformerFactory(clientset, 0),
+	}
+
+	// Register two controllers on the same informer, one of which is disabled
+	keys := make(chan string, 10)
+	registry := NewRegistry()
+	for _, name := range []string{"enabled", "disabled"} {
+		name := name
+		err := registry.Register(name, func(deps Dependencies) (*Controller, error) {
+			informer := deps.Informers.Apps().V1().Deployments().Informer()
+			return New(name, informer, ReconcilerFunc(func(ctx context.Context, key string) (Result, error) {
+				keys <- name + ":" + key
+				return Result{}, nil
+			}))
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Nothing is running yet, so the health checks pass
+	if err := registry.CheckInformersSynced(context.Background()); err != nil {
+		t.Errorf("Expected informer check to pass before start, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- registry.Start(ctx, deps, Options{Enabled: []string{"*", "-disabled"}, Workers: 1})
+	}()
+
+	select {
+	case key := <-keys:
+		if key != "enabled:default/web" {
+			t.Errorf("Expected key 'enabled:default/web', got %s", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reconcile")
+	}
+
+	if err := registry.CheckInformersSynced(context.Background()); err != nil {
+		t.Errorf("Expected informer check to pass after sync, got %v", err)
+	}
+	if err := registry.CheckWorkqueues(time.Minute)(context.Background()); err != nil {
+		t.Errorf("Expected workqueue check to pass, got %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for registry to stop")
+	}
+
+	// The disabled controller never reconciled anything
+	close(keys)
+	for key := range keys {
+		t.Errorf("Unexpected reconcile %s", key)
+	}
+}
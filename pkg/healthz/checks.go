@@ -0,0 +1,20 @@
+package healthz
+
+import (
+	"context"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// KubeAPICheck reports whether the Kubernetes API server is reachable and ready
+func KubeAPICheck(client kubernetes.Interface) Checker {
+	return NamedCheck("kube-api", func(ctx context.Context) error {
+		rc := client.Discovery().RESTClient()
+		if rc == nil {
+			// Fake clientsets have no REST client
+			_, err := client.Discovery().ServerVersion()
+			return err
+		}
+		return rc.Get().AbsPath("/readyz").Do(ctx).Error()
+	})
+}
@@ -0,0 +1,48 @@
+package healthz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestKubeAPICheck(t *testing.T) {
+	ready := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/readyz" || !ready {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	check := KubeAPICheck(client)
+
+	if check.Name() != "kube-api" {
+		t.Errorf("Expected check name 'kube-api', got %s", check.Name())
+	}
+	if err := check.Check(context.Background()); err != nil {
+		t.Errorf("Expected API server to be reachable, got %v", err)
+	}
+
+	ready = false
+	if err := check.Check(context.Background()); err == nil {
+		t.Error("Expected an error when the API server is not ready")
+	}
+}
+
+func TestKubeAPICheckFakeClientset(t *testing.T) {
+	if err := KubeAPICheck(fake.NewClientset()).Check(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package healthz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s-controller/pkg/logger"
+
+	"github.com/valyala/fasthttp"
+)
+
+// log is the healthz component logger
+var log = logger.Component("healthz")
+
+// DefaultCheckTimeout bounds how long a single check may run per request
+const DefaultCheckTimeout = 5 * time.Second
+
+// Checker is a named health check
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type namedCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c namedCheck) Name() string                    { return c.name }
+func (c namedCheck) Check(ctx context.Context) error { return c.check(ctx) }
+
+// NamedCheck returns a Checker that calls the given function
+func NamedCheck(name string, check func(ctx context.Context) error) Checker {
+	return namedCheck{name: name, check: check}
+}
+
+// PingCheck always succeeds; it only shows the endpoint is being served
+var PingCheck = NamedCheck("ping", func(ctx context.Context) error { return nil })
+
+// Registry holds the checks behind one endpoint, such as /healthz or /readyz
+type Registry struct {
+	name    string
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []Checker
+}
+
+// NewRegistry creates a registry for the endpoint name (e.g. "healthz")
+// with the ping check installed
+func NewRegistry(name string) *Registry {
+	return &Registry{
+		name:    name,
+		timeout: DefaultCheckTimeout,
+		checks:  []Checker{PingCheck},
+	}
+}
+
+// Add installs checks; names must be unique within the registry
+func (r *Registry) Add(checks ...Checker) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, check := range checks {
+		for _, existing := range r.checks {
+			if existing.Name() == check.Name() {
+				return fmt.Errorf("%s check %q is already registered", r.name, check.Name())
+			}
+		}
+		r.checks = append(r.checks, check)
+	}
+	return nil
+}
+
+// Names returns the names of the installed checks in registration order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+	for _, check := range r.checks {
+		names = append(names, check.Name())
+	}
+	return names
+}
+
+// Path returns the endpoint path of the registry, e.g. "/healthz"
+func (r *Registry) Path() string {
+	return "/" + r.name
+}
+
+// Handler serves the aggregated endpoint at Path() and each individual check
+// at Path()/<check>, following the kube-apiserver conventions: "?verbose"
+// lists every check, "?exclude=<check>" (repeatable) skips checks, and any
+// failing check turns the response into a 500.
+func (r *Registry) Handler() fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		path := string(ctx.Path())
+		if path == r.Path() || path == r.Path()+"/" {
+			r.serveAll(ctx)
+			return
+		}
+
+		name := strings.TrimPrefix(path, r.Path()+"/")
+		for _, check := range r.snapshot() {
+			if check.Name() == name {
+				r.serveOne(ctx, check)
+				return
+			}
+		}
+
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString("Not found")
+	}
+}
+
+// AggregateHandler serves the aggregated endpoint whatever the request path,
+// for aliases of Path() such as /health
+func (r *Registry) AggregateHandler() fasthttp.RequestHandler {
+	return r.serveAll
+}
+
+func (r *Registry) snapshot() []Checker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Checker(nil), r.checks...)
+}
+
+func (r *Registry) serveAll(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+	verbose := args.Has("verbose")
+
+	excluded := make(map[string]bool)
+	for _, value := range args.PeekMulti("exclude") {
+		for _, name := range strings.Split(string(value), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				excluded[name] = true
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	failed := false
+	for _, check := range r.snapshot() {
+		if excluded[check.Name()] {
+			delete(excluded, check.Name())
+			fmt.Fprintf(&out, "[+]%s excluded: ok\n", check.Name())
+			continue
+		}
+		if err := r.run(check); err != nil {
+			failed = true
+			fmt.Fprintf(&out, "[-]%s failed: reason withheld\n", check.Name())
+			continue
+		}
+		fmt.Fprintf(&out, "[+]%s ok\n", check.Name())
+	}
+
+	if len(excluded) > 0 {
+		names := make([]string, 0, len(excluded))
+		for name := range excluded {
+			names = append(names, fmt.Sprintf("%q", name))
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&out, "warn: some health checks cannot be excluded: no matches for %s\n", strings.Join(names, ","))
+	}
+
+	setTextHeaders(ctx)
+	if failed {
+		fmt.Fprintf(&out, "%s check failed\n", r.name)
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBody(out.Bytes())
+		return
+	}
+
+	if !verbose {
+		ctx.SetBodyString("ok")
+		return
+	}
+	fmt.Fprintf(&out, "%s check passed\n", r.name)
+	ctx.SetBody(out.Bytes())
+}
+
+func (r *Registry) serveOne(ctx *fasthttp.RequestCtx, check Checker) {
+	setTextHeaders(ctx)
+	if err := r.run(check); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(fmt.Sprintf("internal server error: %v", err))
+		return
+	}
+	ctx.SetBodyString("ok")
+}
+
+// run executes a check with the per-check timeout and logs failures
+func (r *Registry) run(check Checker) error {
+	checkCtx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	err := check.Check(checkCtx)
+	if err != nil {
+		log.Warn().Err(err).Str("endpoint", r.name).Str("check", check.Name()).Msg("Health check failed")
+	}
+	return err
+}
+
+func setTextHeaders(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("text/plain; charset=utf-8")
+	ctx.Response.Header.Set("X-Content-Type-Options", "nosniff")
+}
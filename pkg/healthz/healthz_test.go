@@ -0,0 +1,143 @@
+package healthz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s-controller/pkg/logger"
+
+	"github.com/valyala/fasthttp"
+)
+
+// serve runs the registry handler against a synthetic request
+func serve(r *Registry, uri string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(uri)
+	r.Handler()(ctx)
+	return ctx
+}
+
+func TestRegistryAdd(t *testing.T) {
+	r := NewRegistry("healthz")
+
+	if err := r.Add(NamedCheck("foo", func(ctx context.Context) error { return nil })); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.Add(NamedCheck("foo", func(ctx context.Context) error { return nil })); err == nil {
+		t.Error("Expected an error registering a duplicate check")
+	}
+
+	names := r.Names()
+	if len(names) != 2 || names[0] != "ping" || names[1] != "foo" {
+		t.Errorf("Expected checks [ping foo], got %v", names)
+	}
+	if r.Path() != "/healthz" {
+		t.Errorf("Expected path '/healthz', got %s", r.Path())
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	r := NewRegistry("readyz")
+	if err := r.Add(
+		NamedCheck("good", func(ctx context.Context) error { return nil }),
+		NamedCheck("bad", func(ctx context.Context) error { return errors.New("secret detail") }),
+	); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		uri          string
+		expectStatus int
+		expectBody   []string
+		rejectBody   []string
+	}{
+		{
+			name:         "Failing check fails the endpoint",
+			uri:          "/readyz",
+			expectStatus: 500,
+			expectBody:   []string{"[+]ping ok", "[+]good ok", "[-]bad failed: reason withheld", "readyz check failed"},
+			rejectBody:   []string{"secret detail"},
+		},
+		{
+			name:         "Excluding the failing check passes",
+			uri:          "/readyz?exclude=bad",
+			expectStatus: 200,
+			expectBody:   []string{"ok"},
+			rejectBody:   []string{"[+]"},
+		},
+		{
+			name:         "Verbose lists every check",
+			uri:          "/readyz?verbose&exclude=bad",
+			expectStatus: 200,
+			expectBody:   []string{"[+]ping ok", "[+]good ok", "[+]bad excluded: ok", "readyz check passed"},
+		},
+		{
+			name:         "Unknown exclusions are reported",
+			uri:          "/readyz?verbose&exclude=bad&exclude=missing",
+			expectStatus: 200,
+			expectBody:   []string{`warn: some health checks cannot be excluded: no matches for "missing"`},
+		},
+		{
+			name:         "Individual passing check",
+			uri:          "/readyz/good",
+			expectStatus: 200,
+			expectBody:   []string{"ok"},
+		},
+		{
+			name:         "Individual failing check",
+			uri:          "/readyz/bad",
+			expectStatus: 500,
+			expectBody:   []string{"internal server error: secret detail"},
+		},
+		{
+			name:         "Unknown check",
+			uri:          "/readyz/missing",
+			expectStatus: 404,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := serve(r, tc.uri)
+
+			if status := ctx.Response.StatusCode(); status != tc.expectStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectStatus, status)
+			}
+			body := string(ctx.Response.Body())
+			for _, expected := range tc.expectBody {
+				if !strings.Contains(body, expected) {
+					t.Errorf("Expected body to contain %q, got: %s", expected, body)
+				}
+			}
+			for _, rejected := range tc.rejectBody {
+				if strings.Contains(body, rejected) {
+					t.Errorf("Expected body not to contain %q, got: %s", rejected, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryCheckTimeout(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+
+	r := NewRegistry("healthz")
+	r.timeout = 0
+	if err := r.Add(NamedCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := serve(r, "/healthz")
+	if status := ctx.Response.StatusCode(); status != 500 {
+		t.Errorf("Expected a timed out check to fail with 500, got %d", status)
+	}
+}
@@ -0,0 +1,210 @@
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"k8s-controller/pkg/logger"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// log is the leaderelection component logger
+var log = logger.Component("leaderelection")
+
+// Options configures Lease-based leader election
+type Options struct {
+	// LeaseName is the name of the coordination.k8s.io Lease object
+	LeaseName string
+	// LeaseNamespace is the namespace of the Lease object
+	LeaseNamespace string
+	// Identity uniquely identifies this replica; defaults to hostname plus a random suffix
+	Identity string
+	// LeaseDuration is how long non-leaders wait before trying to take over
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the leader retries refreshing before giving up
+	RenewDeadline time.Duration
+	// RetryPeriod is how long clients wait between attempts
+	RetryPeriod time.Duration
+}
+
+// Status describes the leadership state of this replica
+type Status struct {
+	Enabled  bool   `json:"enabled"`
+	Identity string `json:"identity,omitempty"`
+	Leader   string `json:"leader,omitempty"`
+	IsLeader bool   `json:"is_leader"`
+}
+
+// Elector campaigns for a Lease and runs a callback while holding it
+type Elector struct {
+	identity string
+	elector  *leaderelection.LeaderElector
+	run      func(ctx context.Context)
+
+	// shutdown is the context passed to Run; the run callback stops when it
+	// is cancelled, while the elector keeps renewing until the callback returns
+	shutdown context.Context
+
+	mu      sync.Mutex
+	stopped bool
+	running sync.WaitGroup
+}
+
+// New creates an elector for the configured Lease. The run callback is
+// invoked once leadership is acquired; its context is cancelled when
+// leadership is lost or the elector is stopped.
+func New(client kubernetes.Interface, opts Options, run func(ctx context.Context)) (*Elector, error) {
+	if opts.LeaseName == "" {
+		return nil, fmt.Errorf("lease name must not be empty")
+	}
+	if opts.LeaseNamespace == "" {
+		return nil, fmt.Errorf("lease namespace must not be empty")
+	}
+
+	identity := opts.Identity
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname: %w", err)
+		}
+		identity = hostname + "_" + string(uuid.NewUUID())
+	}
+
+	e := &Elector{identity: identity, run: run}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{Namespace: opts.LeaseNamespace, Name: opts.LeaseName},
+		Client:    client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   opts.LeaseDuration,
+		RenewDeadline:   opts.RenewDeadline,
+		RetryPeriod:     opts.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            opts.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: e.onStartedLeading,
+			OnStoppedLeading: e.onStoppedLeading,
+			OnNewLeader:      e.onNewLeader,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create leader elector: %w", err)
+	}
+	e.elector = elector
+
+	return e, nil
+}
+
+// Run campaigns for leadership and blocks until the context is cancelled or
+// leadership is lost, and waits for the run callback to return. When the
+// context is cancelled the Lease is held until the callback has returned, so
+// no other replica takes over while its work is still in flight, and then
+// released.
+func (e *Elector) Run(ctx context.Context) {
+	log.Info().
+		Str("identity", e.identity).
+		Msg("Starting leader election")
+
+	e.shutdown = ctx
+	electorCtx, cancelElector := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelElector()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.elector.Run(electorCtx)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-done:
+	}
+
+	// OnStartedLeading runs in its own goroutine; make sure it cannot start
+	// after this point and wait for it if it already did
+	e.mu.Lock()
+	e.stopped = true
+	e.mu.Unlock()
+	e.running.Wait()
+
+	cancelElector()
+	<-done
+}
+
+// Identity returns the identity this replica campaigns with
+func (e *Elector) Identity() string {
+	return e.identity
+}
+
+// IsLeader reports whether this replica currently holds the Lease
+func (e *Elector) IsLeader() bool {
+	return e.elector.IsLeader()
+}
+
+// Check returns an error if this replica believes it leads but has failed to
+// renew the Lease for longer than the lease duration plus tolerance. It is
+// safe to call on a nil Elector.
+func (e *Elector) Check(tolerance time.Duration) error {
+	if e == nil {
+		return nil
+	}
+	return e.elector.Check(tolerance)
+}
+
+// Status returns the leadership state. It is safe to call on a nil Elector,
+// which reports leader election as disabled.
+func (e *Elector) Status() Status {
+	if e == nil {
+		return Status{Enabled: false}
+	}
+	return Status{
+		Enabled:  true,
+		Identity: e.identity,
+		Leader:   e.elector.GetLeader(),
+		IsLeader: e.elector.IsLeader(),
+	}
+}
+
+func (e *Elector) onStartedLeading(ctx context.Context) {
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return
+	}
+	e.running.Add(1)
+	e.mu.Unlock()
+	defer e.running.Done()
+
+	// Stop the callback on shutdown as well as when leadership is lost
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(e.shutdown, cancel)
+	defer stop()
+
+	log.Info().Str("identity", e.identity).Msg("Acquired leadership")
+	e.run(ctx)
+}
+
+func (e *Elector) onStoppedLeading() {
+	log.Info().Str("identity", e.identity).Msg("Stopped leading")
+}
+
+func (e *Elector) onNewLeader(identity string) {
+	if identity == e.identity {
+		return
+	}
+	log.Info().Str("leader", identity).Msg("New leader elected")
+}
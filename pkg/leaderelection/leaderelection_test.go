@@ -0,0 +1,227 @@
+package leaderelection
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"k8s-controller/pkg/logger"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testOptions(identity string) Options {
+	return Options{
+		LeaseName:      "test-lease",
+		LeaseNamespace: "default",
+		Identity:       identity,
+		LeaseDuration:  2 * time.Second,
+		RenewDeadline:  1 * time.Second,
+		RetryPeriod:    100 * time.Millisecond,
+	}
+}
+
+func TestNewValidatesOptions(t *testing.T) {
+	client := fake.NewClientset()
+	noop := func(ctx context.Context) {}
+
+	opts := testOptions("a")
+	opts.LeaseName = ""
+	if _, err := New(client, opts, noop); err == nil {
+		t.Error("Expected an error for an empty lease name")
+	}
+
+	opts = testOptions("a")
+	opts.LeaseNamespace = ""
+	if _, err := New(client, opts, noop); err == nil {
+		t.Error("Expected an error for an empty lease namespace")
+	}
+
+	opts = testOptions("a")
+	opts.RenewDeadline = opts.LeaseDuration
+	if _, err := New(client, opts, noop); err == nil {
+		t.Error("Expected an error when renew deadline is not shorter than lease duration")
+	}
+
+	opts = testOptions("")
+	elector, err := New(client, opts, noop)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elector.Identity() == "" {
+		t.Error("Expected a generated identity")
+	}
+}
+
+func TestNilElectorStatus(t *testing.T) {
+	var elector *Elector
+	if status := elector.Status(); status.Enabled {
+		t.Error("Expected a nil elector to report leader election as disabled")
+	}
+}
+
+func TestElectorFailover(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+	client := fake.NewClientset()
+
+	leading := make(chan string, 2)
+	newElector := func(identity string) *Elector {
+		elector, err := New(client, testOptions(identity), func(ctx context.Context) {
+			leading <- identity
+			<-ctx.Done()
+		})
+		if err != nil {
+			t.Fatalf("Failed to create elector: %v", err)
+		}
+		return elector
+	}
+
+	first := newElector("first")
+	second := newElector("second")
+
+	// First replica acquires the lease
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	firstDone := make(chan struct{})
+	go func() {
+		first.Run(firstCtx)
+		close(firstDone)
+	}()
+
+	select {
+	case identity := <-leading:
+		if identity != "first" {
+			t.Fatalf("Expected 'first' to lead, got %s", identity)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for first replica to lead")
+	}
+
+	status := first.Status()
+	if !status.Enabled || !status.IsLeader || status.Leader != "first" {
+		t.Errorf("Unexpected status for leader: %+v", status)
+	}
+
+	// Second replica waits while the lease is held
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	go second.Run(secondCtx)
+
+	time.Sleep(300 * time.Millisecond)
+	if second.IsLeader() {
+		t.Error("Expected second replica not to lead while the lease is held")
+	}
+
+	// Stopping the leader releases the lease so the second replica takes over
+	stopFirst()
+	select {
+	case <-firstDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for first replica to stop")
+	}
+
+	lease, err := client.CoordinationV1().Leases("default").Get(context.Background(), "test-lease", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get lease: %v", err)
+	}
+	if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == "first" {
+		t.Error("Expected the lease to be released on shutdown")
+	}
+
+	select {
+	case identity := <-leading:
+		if identity != "second" {
+			t.Errorf("Expected 'second' to lead, got %s", identity)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for second replica to take over")
+	}
+}
+
+func TestElectorCheck(t *testing.T) {
+	var elector *Elector
+	if err := elector.Check(time.Second); err != nil {
+		t.Errorf("Expected a nil elector to be healthy, got %v", err)
+	}
+
+	// A replica that is not leading is always healthy
+	elector, err := New(fake.NewClientset(), testOptions("standby"), func(ctx context.Context) {})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := elector.Check(time.Second); err != nil {
+		t.Errorf("Expected a standby elector to be healthy, got %v", err)
+	}
+}
+
+func TestElectorHoldsLeaseWhileRunDrains(t *testing.T) {
+	logger.SetOutput(new(bytes.Buffer))
+	client := fake.NewClientset()
+
+	leading := make(chan struct{})
+	stopping := make(chan struct{})
+	release := make(chan struct{})
+	elector, err := New(client, testOptions("draining"), func(ctx context.Context) {
+		close(leading)
+		<-ctx.Done()
+		// Simulate an in-flight reconcile that outlives the cancellation
+		close(stopping)
+		<-release
+	})
+	if err != nil {
+		t.Fatalf("Failed to create elector: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		elector.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-leading:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the elector to lead")
+	}
+
+	cancel()
+	select {
+	case <-stopping:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the run callback to be cancelled")
+	}
+
+	holder := func() string {
+		lease, err := client.CoordinationV1().Leases("default").Get(context.Background(), "test-lease", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get lease: %v", err)
+		}
+		if lease.Spec.HolderIdentity == nil {
+			return ""
+		}
+		return *lease.Spec.HolderIdentity
+	}
+
+	// Wait past a few renewals; the Lease must not be given up yet
+	time.Sleep(300 * time.Millisecond)
+	if got := holder(); got != "draining" {
+		t.Errorf("Expected the lease to be held while the run callback drains, got holder %q", got)
+	}
+	select {
+	case <-done:
+		t.Fatal("Expected Run to wait for the run callback")
+	default:
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the elector to stop")
+	}
+	if got := holder(); got == "draining" {
+		t.Error("Expected the lease to be released once the run callback returned")
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// Fields are key/value pairs added to every line of a child logger
+type Fields map[string]interface{}
+
+// contextKey is the context key under which a logger is stored
+type contextKey struct{}
+
+// With returns a child of the global logger that adds fields to every line
+func With(fields Fields) *zerolog.Logger {
+	l := log.With().Fields(map[string]interface{}(fields)).Logger()
+	return &l
+}
+
+// Global returns the package-global logger
+func Global() *zerolog.Logger {
+	return &log
+}
+
+// IntoContext returns a context carrying l. A fasthttp.RequestCtx, or any
+// context with a SetUserValue method, stores l as a user value and is
+// returned as is, so handlers further down the chain find it.
+func IntoContext(ctx context.Context, l *zerolog.Logger) context.Context {
+	if uv, ok := ctx.(interface{ SetUserValue(key, value any) }); ok {
+		uv.SetUserValue(contextKey{}, l)
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by IntoContext, or the
+// global logger when there is none
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*zerolog.Logger); ok {
+			return l
+		}
+	}
+	return &log
+}
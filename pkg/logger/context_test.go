@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestWith(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	SetOutputFormat(buffer, JSONFormat)
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	l := With(Fields{"controller": "deployment", "key": "default/web"})
+	l.Info().Msg("scoped message")
+	Info().Msg("global message")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buffer.String())
+	}
+	for _, field := range []string{`"controller":"deployment"`, `"key":"default/web"`} {
+		if !strings.Contains(lines[0], field) {
+			t.Errorf("Expected scoped line to contain %s, got %s", field, lines[0])
+		}
+		if strings.Contains(lines[1], field) {
+			t.Errorf("Expected global line not to contain %s, got %s", field, lines[1])
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	// Without a logger the global one is returned
+	if FromContext(context.Background()) != Global() {
+		t.Error("Expected the global logger for an empty context")
+	}
+
+	l := With(Fields{"request_id": "abc"})
+	ctx := IntoContext(context.Background(), l)
+	if FromContext(ctx) != l {
+		t.Error("Expected the logger stored in the context")
+	}
+
+	// Derived contexts keep the logger
+	child, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if FromContext(child) != l {
+		t.Error("Expected the logger to be inherited by derived contexts")
+	}
+}
+
+func TestContextRequestCtx(t *testing.T) {
+	reqCtx := &fasthttp.RequestCtx{}
+	l := With(Fields{"request_id": "abc"})
+
+	if ctx := IntoContext(reqCtx, l); ctx != reqCtx {
+		t.Error("Expected the RequestCtx to be returned as is")
+	}
+	if FromContext(reqCtx) != l {
+		t.Error("Expected the logger stored as a user value")
+	}
+}
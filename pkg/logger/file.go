@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileOptions configures log file output. The file is rotated when it
+// reaches MaxSizeMB and, if set, every RotateInterval. Rotated files are
+// named after the file with a timestamp and removed once there are more
+// than MaxBackups of them or they are older than MaxAgeDays.
+type FileOptions struct {
+	// Path of the log file; its directory is created if needed
+	Path string
+	// Format of the file, JSON when empty
+	Format LogFormat
+	// MaxSizeMB is the size in megabytes at which the file is rotated
+	MaxSizeMB int
+	// MaxAgeDays removes rotated files older than this many days; 0 keeps them
+	MaxAgeDays int
+	// MaxBackups is the number of rotated files kept; 0 keeps all of them
+	MaxBackups int
+	// Compress gzips rotated files
+	Compress bool
+	// RotateInterval also rotates the file periodically; 0 rotates by size only
+	RotateInterval time.Duration
+}
+
+func (o FileOptions) format() LogFormat {
+	if o.Format == "" {
+		return JSONFormat
+	}
+	return o.Format
+}
+
+// rotatingFile is a lumberjack logger that can also rotate on an interval
+type rotatingFile struct {
+	*lumberjack.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     sync.WaitGroup
+}
+
+func newRotatingFile(opts FileOptions) (*rotatingFile, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("log file path must not be empty")
+	}
+
+	f := &rotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   opts.Path,
+			MaxSize:    opts.MaxSizeMB,
+			MaxAge:     opts.MaxAgeDays,
+			MaxBackups: opts.MaxBackups,
+			Compress:   opts.Compress,
+		},
+		stop: make(chan struct{}),
+	}
+
+	// Open the file now so a bad path fails at startup rather than on the
+	// first log line
+	if _, err := f.Write(nil); err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", opts.Path, err)
+	}
+
+	if opts.RotateInterval > 0 {
+		f.done.Add(1)
+		go f.rotateEvery(opts.RotateInterval)
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) rotateEvery(interval time.Duration) {
+	defer f.done.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			if err := f.Rotate(); err != nil {
+				Error().Err(err).Str("file", f.Filename).Msg("Failed to rotate log file")
+			}
+		}
+	}
+}
+
+// Close stops interval rotation and closes the file
+func (f *rotatingFile) Close() error {
+	f.stopOnce.Do(func() { close(f.stop) })
+	f.done.Wait()
+	return f.Logger.Close()
+}
+
+// nopCloser is returned when there is no file to close
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
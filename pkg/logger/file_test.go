@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileOutputTee(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "controller.log")
+	file, err := newRotatingFile(FileOptions{Path: path, MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	stdout := new(bytes.Buffer)
+	SetOutputs(
+		Output{Writer: stdout, Format: LogfmtFormat},
+		Output{Writer: file, Format: JSONFormat},
+	)
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	Info().Str("key", "value").Msg("tee message")
+
+	if !strings.Contains(stdout.String(), `msg="tee message" key=value`) {
+		t.Errorf("Expected logfmt line on stdout, got %q", stdout.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("Expected a JSON line in the file, got %q: %v", data, err)
+	}
+	if event["msg"] != "tee message" {
+		t.Errorf("Unexpected event in file: %v", event)
+	}
+}
+
+func TestFileRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "controller.log")
+	file, err := newRotatingFile(FileOptions{
+		Path:           path,
+		MaxSizeMB:      1,
+		MaxBackups:     1,
+		Compress:       true,
+		RotateInterval: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	SetOutputs(Output{Writer: file, Format: JSONFormat})
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	// Interval rotation produces compressed backups, of which one is kept
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		Info().Msg("rotated message")
+		matches, _ := filepath.Glob(filepath.Join(dir, "controller-*.log.gz"))
+		if len(matches) == 1 {
+			break
+		}
+		if len(matches) > 1 {
+			// Old backups are removed asynchronously
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		if time.Now().After(deadline) {
+			entries, _ := os.ReadDir(dir)
+			t.Fatalf("Timed out waiting for a compressed backup, found %v", entries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestInitWithOptionsFileOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "controller.log")
+	closer, err := InitWithOptions(Options{
+		Level:         InfoLevel,
+		Format:        ConsoleFormat,
+		File:          &FileOptions{Path: path},
+		DisableStdout: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init logger: %v", err)
+	}
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	Info().Msg("file only")
+	if err := closer.Close(); err != nil {
+		t.Errorf("Failed to close log file: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !json.Valid(bytes.TrimSpace(data)) || !bytes.Contains(data, []byte("file only")) {
+		t.Errorf("Expected a JSON line in the file, got %q", data)
+	}
+}
+
+func TestInitWithOptionsBadPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	// A regular file cannot be used as the log directory
+	if _, err := InitWithOptions(Options{File: &FileOptions{Path: filepath.Join(path, "controller.log")}}); err == nil {
+		t.Error("Expected an error for an unusable log path")
+	}
+}
@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/term"
+)
+
+// LogFormat represents available output formats
+type LogFormat string
+
+const (
+	// ConsoleFormat writes colored, human-friendly lines.
+	ConsoleFormat LogFormat = "console"
+	// JSONFormat writes one JSON object per line.
+	JSONFormat LogFormat = "json"
+	// LogfmtFormat writes key=value pairs, one event per line.
+	LogfmtFormat LogFormat = "logfmt"
+)
+
+// currentFormat is the format used by SetOutput
+var currentFormat = ConsoleFormat
+
+// IsValid reports whether f is one of the supported formats
+func (f LogFormat) IsValid() bool {
+	switch f {
+	case ConsoleFormat, JSONFormat, LogfmtFormat:
+		return true
+	}
+	return false
+}
+
+// DefaultFormat returns ConsoleFormat when f is a terminal and JSONFormat
+// otherwise, so logs collected from containers are machine readable
+func DefaultFormat(f *os.File) LogFormat {
+	if term.IsTerminal(int(f.Fd())) {
+		return ConsoleFormat
+	}
+	return JSONFormat
+}
+
+// newFormatWriter wraps w so zerolog's JSON events are written in format
+func newFormatWriter(w io.Writer, format LogFormat) io.Writer {
+	switch format {
+	case JSONFormat:
+		return w
+	case LogfmtFormat:
+		return logfmtWriter{out: w}
+	default:
+		return newConsoleWriter(w)
+	}
+}
+
+func newConsoleWriter(w io.Writer) zerolog.ConsoleWriter {
+	output := zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339}
+	output.FormatLevel = func(i interface{}) string {
+		return strings.ToUpper(fmt.Sprintf("| %-6s|", i))
+	}
+	output.FormatMessage = func(i interface{}) string {
+		return fmt.Sprintf("%s", i)
+	}
+	output.FormatFieldName = func(i interface{}) string {
+		return fmt.Sprintf("%s:", i)
+	}
+	output.FormatFieldValue = func(i interface{}) string {
+		return fmt.Sprintf("%s", i)
+	}
+	return output
+}
+
+// shortCaller reports the caller as dir/file.go:line
+func shortCaller(_ uintptr, file string, line int) string {
+	return filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)) + ":" + strconv.Itoa(line)
+}
+
+// logfmtWriter re-encodes zerolog's JSON events as logfmt. The ts, level,
+// caller and msg fields come first, the others keep their order.
+type logfmtWriter struct {
+	out io.Writer
+}
+
+// leadingFields are written before every other field
+var leadingFields = []string{"ts", "level", "caller", "msg"}
+
+func (w logfmtWriter) Write(p []byte) (int, error) {
+	fields, err := decodeFields(p)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode log event: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, name := range leadingFields {
+		for _, f := range fields {
+			if f.key == name {
+				appendPair(&buf, f.key, f.value)
+			}
+		}
+	}
+	for _, f := range fields {
+		if !isLeadingField(f.key) {
+			appendPair(&buf, f.key, f.value)
+		}
+	}
+	buf.WriteByte('\n')
+
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+type field struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeFields returns the top-level fields of a JSON object in order
+func decodeFields(p []byte) ([]field, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var fields []field
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field{key: key, value: value})
+	}
+	return fields, nil
+}
+
+func isLeadingField(key string) bool {
+	for _, name := range leadingFields {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// appendPair writes key=value, quoting strings that need it and keeping
+// numbers, booleans, objects and arrays in their compact JSON form
+func appendPair(buf *bytes.Buffer, key string, raw json.RawMessage) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		// Not a string: numbers and booleans are written as is, objects
+		// and arrays as quoted JSON when they contain special characters
+		value := string(raw)
+		if strings.ContainsAny(value, " =\"") {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(value)
+		return
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\n\r\\") {
+		s = strconv.Quote(s)
+	}
+	buf.WriteString(s)
+}
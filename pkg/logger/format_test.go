@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJSONFormat(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	SetOutputFormat(buffer, JSONFormat)
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	Info().Str("component", "test").Msg("json message")
+
+	var event map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &event); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", buffer.String(), err)
+	}
+	for _, key := range []string{"ts", "level", "caller", "msg", "component"} {
+		if _, ok := event[key]; !ok {
+			t.Errorf("Expected field %q in %v", key, event)
+		}
+	}
+	if event["msg"] != "json message" || event["level"] != "info" {
+		t.Errorf("Unexpected event: %v", event)
+	}
+	if caller, _ := event["caller"].(string); !strings.HasPrefix(caller, "logger/format_test.go:") {
+		t.Errorf("Expected caller to be dir/file:line, got %q", caller)
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	SetOutputFormat(buffer, LogfmtFormat)
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	Warn().Str("path", "/api").Int("status", 404).Str("agent", "curl 8").
+		Interface("tags", []string{"a"}).Msg("request failed")
+
+	line := strings.TrimSpace(buffer.String())
+	if !strings.HasPrefix(line, "ts=") {
+		t.Errorf("Expected line to start with ts=, got %q", line)
+	}
+	expected := []string{
+		" level=warn caller=logger/format_test.go:",
+		` msg="request failed" path=/api status=404 agent="curl 8" tags="[\"a\"]"`,
+	}
+	for _, part := range expected {
+		if !strings.Contains(line, part) {
+			t.Errorf("Expected %q in %q", part, line)
+		}
+	}
+}
+
+func TestSetOutputKeepsFormat(t *testing.T) {
+	SetOutputFormat(new(bytes.Buffer), JSONFormat)
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	buffer := new(bytes.Buffer)
+	SetOutput(buffer)
+	Info().Msg("still json")
+
+	if !json.Valid(buffer.Bytes()) {
+		t.Errorf("Expected SetOutput to keep the JSON format, got %q", buffer.String())
+	}
+}
+
+func TestLogFormatIsValid(t *testing.T) {
+	for _, format := range []LogFormat{ConsoleFormat, JSONFormat, LogfmtFormat} {
+		if !format.IsValid() {
+			t.Errorf("Expected %s to be valid", format)
+		}
+	}
+	if LogFormat("xml").IsValid() {
+		t.Error("Expected 'xml' to be invalid")
+	}
+}
+
+func TestDefaultFormat(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if format := DefaultFormat(w); format != JSONFormat {
+		t.Errorf("Expected JSON format for a pipe, got %s", format)
+	}
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/valyala/fasthttp"
+)
+
+// LevelRequest is the body accepted by LevelHandler on PUT. An empty
+// Component changes the default level; an empty Level resets a component.
+type LevelRequest struct {
+	Component string   `json:"component"`
+	Level     LogLevel `json:"level"`
+}
+
+// LevelHandler serves the log levels: GET returns the LevelStatus and PUT
+// applies a LevelRequest, then returns the new LevelStatus
+func LevelHandler() fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		switch {
+		case ctx.IsGet():
+		case ctx.IsPut():
+			var req LevelRequest
+			if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+				ctx.Error(fmt.Sprintf("invalid request body: %v", err), fasthttp.StatusBadRequest)
+				return
+			}
+			if err := applyLevelRequest(req); err != nil {
+				ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+				return
+			}
+			FromContext(ctx).Info().
+				Str("target", req.Component).
+				Str("new_level", string(req.Level)).
+				Msg("Log level changed")
+		default:
+			// Error resets the response, so the Allow header comes after it
+			ctx.Error("method not allowed", fasthttp.StatusMethodNotAllowed)
+			ctx.Response.Header.Set(fasthttp.HeaderAllow, "GET, PUT")
+			return
+		}
+
+		ctx.SetContentType("application/json")
+		if err := json.NewEncoder(ctx).Encode(Levels()); err != nil {
+			FromContext(ctx).Error().Err(err).Msg("Failed to write log levels")
+		}
+	}
+}
+
+func applyLevelRequest(req LevelRequest) error {
+	if req.Component != "" {
+		return SetComponentLevel(req.Component, req.Level)
+	}
+	if !req.Level.IsValid() {
+		return fmt.Errorf("invalid log level %q", req.Level)
+	}
+	SetLevel(req.Level)
+	return nil
+}
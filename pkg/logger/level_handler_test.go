@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func serveLevels(t *testing.T, method, body string) (*fasthttp.RequestCtx, LevelStatus) {
+	t.Helper()
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI("/debug/loglevel")
+	ctx.Request.SetBodyString(body)
+	LevelHandler()(ctx)
+
+	var status LevelStatus
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		if err := json.Unmarshal(ctx.Response.Body(), &status); err != nil {
+			t.Fatalf("Failed to decode response %q: %v", ctx.Response.Body(), err)
+		}
+	}
+	return ctx, status
+}
+
+func TestLevelHandler(t *testing.T) {
+	resetLevels(t)
+	SetLevel(InfoLevel)
+
+	_, status := serveLevels(t, "GET", "")
+	if status.Level != InfoLevel {
+		t.Errorf("Expected default level info, got %s", status.Level)
+	}
+
+	_, status = serveLevels(t, "PUT", `{"component":"controller.foo","level":"trace"}`)
+	if status.Overrides["controller.foo"] != TraceLevel {
+		t.Errorf("Expected controller.foo override at trace, got %v", status.Overrides)
+	}
+
+	_, status = serveLevels(t, "PUT", `{"level":"debug"}`)
+	if status.Level != DebugLevel {
+		t.Errorf("Expected default level debug, got %s", status.Level)
+	}
+}
+
+func TestLevelHandlerErrors(t *testing.T) {
+	resetLevels(t)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"invalid json", "PUT", `{`, fasthttp.StatusBadRequest},
+		{"invalid level", "PUT", `{"component":"http","level":"loud"}`, fasthttp.StatusBadRequest},
+		{"missing level", "PUT", `{}`, fasthttp.StatusBadRequest},
+		{"wrong method", "POST", `{"level":"debug"}`, fasthttp.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := serveLevels(t, tt.method, tt.body)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, ctx.Response.StatusCode())
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// levels holds the default level and the per-component overrides
+var levels = newLevelRegistry()
+
+// levelRegistry tracks component levels. Lookups read an immutable snapshot
+// so the hook on every log event does not take a lock.
+type levelRegistry struct {
+	mu         sync.Mutex
+	defaultLvl zerolog.Level
+	overrides  map[string]zerolog.Level
+	components map[string]struct{}
+
+	snapshot atomic.Pointer[levelSnapshot]
+}
+
+type levelSnapshot struct {
+	defaultLvl zerolog.Level
+	overrides  map[string]zerolog.Level
+}
+
+func newLevelRegistry() *levelRegistry {
+	r := &levelRegistry{
+		// zerolog's own default until Init sets a level
+		defaultLvl: zerolog.DebugLevel,
+		overrides:  make(map[string]zerolog.Level),
+		components: make(map[string]struct{}),
+	}
+	r.publishLocked()
+	return r
+}
+
+// levelFor returns the level of a component: its own override, else the
+// override of the closest parent ("controller" for "controller.foo"), else
+// the default level. The empty name is the default logger.
+func (r *levelRegistry) levelFor(name string) zerolog.Level {
+	snap := r.snapshot.Load()
+	for name != "" {
+		if lvl, ok := snap.overrides[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return snap.defaultLvl
+}
+
+func (r *levelRegistry) setDefault(lvl zerolog.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultLvl = lvl
+	r.publishLocked()
+}
+
+func (r *levelRegistry) setOverride(name string, lvl zerolog.Level, reset bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reset {
+		delete(r.overrides, name)
+	} else {
+		r.overrides[name] = lvl
+	}
+	r.publishLocked()
+}
+
+func (r *levelRegistry) register(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components[name] = struct{}{}
+}
+
+// publishLocked stores a new snapshot and lowers the zerolog global level to
+// the most verbose configured level, so events of verbose components are
+// created and the hooks can filter the rest
+func (r *levelRegistry) publishLocked() {
+	overrides := make(map[string]zerolog.Level, len(r.overrides))
+	lowest := r.defaultLvl
+	for name, lvl := range r.overrides {
+		overrides[name] = lvl
+		if lvl < lowest {
+			lowest = lvl
+		}
+	}
+	r.snapshot.Store(&levelSnapshot{defaultLvl: r.defaultLvl, overrides: overrides})
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// levelHook discards events below the level of its component and events
+// dropped by sampling
+type levelHook struct {
+	component string
+}
+
+func (h levelHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level < levels.levelFor(h.component) && level != zerolog.NoLevel {
+		e.Discard()
+		return
+	}
+	if smp := sampling.Load(); smp != nil && !smp.keep(level, msg, time.Now()) {
+		e.Discard()
+	}
+}
+
+// Component returns a logger for a named component such as "http",
+// "leaderelection" or "controller.deployment". Its lines carry a component
+// field and its level follows SetComponentLevel; dotted names inherit the
+// level of their parent ("controller" for "controller.deployment").
+func Component(name string) *zerolog.Logger {
+	levels.register(name)
+	l := base.With().Str("component", name).Logger().Hook(levelHook{component: name})
+	return &l
+}
+
+// SetComponentLevel sets the level of a component and the components below
+// it. An empty level removes the override so the component follows its
+// parent or the default level again.
+func SetComponentLevel(name string, level LogLevel) error {
+	if name == "" {
+		return fmt.Errorf("component name must not be empty")
+	}
+	if level == "" {
+		levels.setOverride(name, zerolog.NoLevel, true)
+		return nil
+	}
+	if !level.IsValid() {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	levels.setOverride(name, level.zerologLevel(), false)
+	return nil
+}
+
+// LevelStatus describes the default level and the effective level of every
+// known component
+type LevelStatus struct {
+	Level      LogLevel            `json:"level"`
+	Components map[string]LogLevel `json:"components"`
+	Overrides  map[string]LogLevel `json:"overrides"`
+}
+
+// Levels returns the default level, the effective level of every component
+// created with Component and the configured overrides
+func Levels() LevelStatus {
+	levels.mu.Lock()
+	names := make([]string, 0, len(levels.components)+len(levels.overrides))
+	for name := range levels.components {
+		names = append(names, name)
+	}
+	overrides := make(map[string]LogLevel, len(levels.overrides))
+	for name, lvl := range levels.overrides {
+		overrides[name] = LogLevel(lvl.String())
+		names = append(names, name)
+	}
+	defaultLvl := levels.defaultLvl
+	levels.mu.Unlock()
+
+	sort.Strings(names)
+	components := make(map[string]LogLevel, len(names))
+	for _, name := range names {
+		components[name] = LogLevel(levels.levelFor(name).String())
+	}
+	return LevelStatus{
+		Level:      LogLevel(defaultLvl.String()),
+		Components: components,
+		Overrides:  overrides,
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// resetLevels restores the default level and drops component overrides
+func resetLevels(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		for name := range Levels().Overrides {
+			_ = SetComponentLevel(name, "")
+		}
+		SetLevel(InfoLevel)
+	})
+}
+
+func TestComponentLevels(t *testing.T) {
+	resetLevels(t)
+	buffer := new(bytes.Buffer)
+	SetOutputFormat(buffer, JSONFormat)
+	defer SetOutputFormat(new(bytes.Buffer), ConsoleFormat)
+
+	SetLevel(InfoLevel)
+	foo := Component("controller.foo")
+	bar := Component("controller.bar")
+
+	if err := SetComponentLevel("controller.foo", TraceLevel); err != nil {
+		t.Fatalf("Failed to set level: %v", err)
+	}
+	foo.Trace().Msg("foo trace")
+	bar.Debug().Msg("bar debug")
+	Debug().Msg("global debug")
+	bar.Info().Msg("bar info")
+
+	out := buffer.String()
+	if !strings.Contains(out, "foo trace") || !strings.Contains(out, `"component":"controller.foo"`) {
+		t.Errorf("Expected trace line of controller.foo, got %s", out)
+	}
+	if strings.Contains(out, "bar debug") || strings.Contains(out, "global debug") {
+		t.Errorf("Expected other loggers to stay at info, got %s", out)
+	}
+	if !strings.Contains(out, "bar info") {
+		t.Errorf("Expected info line of controller.bar, got %s", out)
+	}
+
+	// A parent override applies to every child without its own
+	buffer.Reset()
+	if err := SetComponentLevel("controller", ErrorLevel); err != nil {
+		t.Fatalf("Failed to set level: %v", err)
+	}
+	bar.Warn().Msg("bar warn")
+	foo.Trace().Msg("foo trace again")
+	if strings.Contains(buffer.String(), "bar warn") {
+		t.Error("Expected controller.bar to inherit the error level of controller")
+	}
+	if !strings.Contains(buffer.String(), "foo trace again") {
+		t.Error("Expected controller.foo to keep its own level")
+	}
+
+	// Resetting removes the override
+	buffer.Reset()
+	if err := SetComponentLevel("controller.foo", ""); err != nil {
+		t.Fatalf("Failed to reset level: %v", err)
+	}
+	foo.Warn().Msg("foo warn")
+	if strings.Contains(buffer.String(), "foo warn") {
+		t.Error("Expected controller.foo to follow controller after reset")
+	}
+}
+
+func TestSetComponentLevelRejectsInvalid(t *testing.T) {
+	if err := SetComponentLevel("http", "loud"); err == nil {
+		t.Error("Expected an error for an invalid level")
+	}
+	if err := SetComponentLevel("", DebugLevel); err == nil {
+		t.Error("Expected an error for an empty component")
+	}
+}
+
+func TestLevels(t *testing.T) {
+	resetLevels(t)
+	SetLevel(WarnLevel)
+	Component("leaderelection")
+	if err := SetComponentLevel("http", DebugLevel); err != nil {
+		t.Fatalf("Failed to set level: %v", err)
+	}
+
+	status := Levels()
+	if status.Level != WarnLevel {
+		t.Errorf("Expected default level warn, got %s", status.Level)
+	}
+	if status.Components["leaderelection"] != WarnLevel {
+		t.Errorf("Expected leaderelection at warn, got %s", status.Components["leaderelection"])
+	}
+	if status.Components["http"] != DebugLevel || status.Overrides["http"] != DebugLevel {
+		t.Errorf("Expected http override at debug, got %v", status)
+	}
+}
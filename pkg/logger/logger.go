@@ -1,16 +1,24 @@
 package logger
 
 import (
-	"fmt"
 	"io"
 	"os"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
-var log zerolog.Logger
+var (
+	// output forwards events to the writer set by SetOutput, so loggers
+	// derived from log keep working when the output changes
+	output = &swappableWriter{}
+
+	// base carries no level hook; log and component loggers add their own
+	base = zerolog.New(output).With().Timestamp().Caller().Logger()
+
+	log = base.Hook(levelHook{})
+)
 
 // LogLevel represents available log levels
 type LogLevel string
@@ -28,64 +36,163 @@ const (
 	TraceLevel LogLevel = "trace"
 )
 
-// Init initializes the logger with the specified log level
-func Init(level LogLevel) {
+func init() {
+	// Field names shared by every output format
+	zerolog.TimestampFieldName = "ts"
+	zerolog.LevelFieldName = "level"
+	zerolog.CallerFieldName = "caller"
+	zerolog.MessageFieldName = "msg"
 	zerolog.TimeFieldFormat = time.RFC3339
-	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
-	output.FormatLevel = func(i interface{}) string {
-		return strings.ToUpper(fmt.Sprintf("| %-6s|", i))
-	}
-	output.FormatMessage = func(i interface{}) string {
-		return fmt.Sprintf("%s", i)
+	zerolog.CallerMarshalFunc = shortCaller
+}
+
+// Init initializes the logger with the specified log level, writing to stdout
+// in the default format for stdout
+func Init(level LogLevel) {
+	InitWithFormat(level, DefaultFormat(os.Stdout))
+}
+
+// InitWithFormat initializes the logger with the specified log level and
+// output format, writing to stdout. An empty format selects DefaultFormat.
+func InitWithFormat(level LogLevel, format LogFormat) {
+	// Without a file there is nothing that can fail
+	_, _ = InitWithOptions(Options{Level: level, Format: format})
+}
+
+// Options configures InitWithOptions
+type Options struct {
+	// Level is the default log level
+	Level LogLevel
+	// Format of stdout; empty selects DefaultFormat
+	Format LogFormat
+	// File enables log file output when not nil
+	File *FileOptions
+	// DisableStdout stops writing to stdout, only with File set
+	DisableStdout bool
+}
+
+// InitWithOptions initializes the logger, writing to stdout, a rotated log
+// file or both. The returned Closer stops rotation and closes the file.
+func InitWithOptions(opts Options) (io.Closer, error) {
+	format := opts.Format
+	if format == "" {
+		format = DefaultFormat(os.Stdout)
 	}
-	output.FormatFieldName = func(i interface{}) string {
-		return fmt.Sprintf("%s:", i)
+
+	var outputs []Output
+	if opts.File == nil || !opts.DisableStdout {
+		outputs = append(outputs, Output{Writer: os.Stdout, Format: format})
 	}
-	output.FormatFieldValue = func(i interface{}) string {
-		return fmt.Sprintf("%s", i)
+
+	closer := io.Closer(nopCloser{})
+	if opts.File != nil {
+		file, err := newRotatingFile(*opts.File)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, Output{Writer: file, Format: opts.File.format()})
+		closer = file
 	}
 
-	multi := zerolog.MultiLevelWriter(output)
-	log = zerolog.New(multi).With().Timestamp().Caller().Logger()
+	SetOutputs(outputs...)
+	setLogLevel(opts.Level)
+	return closer, nil
+}
 
+// SetLevel changes the log level of a running logger
+func SetLevel(level LogLevel) {
 	setLogLevel(level)
 }
 
-// setLogLevel sets the logger level
+// IsValid reports whether l is one of the supported log levels
+func (l LogLevel) IsValid() bool {
+	switch l {
+	case TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel:
+		return true
+	}
+	return false
+}
+
+// setLogLevel sets the default level, used by loggers of components
+// without a level of their own. Unknown levels fall back to info.
 func setLogLevel(level LogLevel) {
-	switch level {
+	if !level.IsValid() {
+		level = InfoLevel
+	}
+	levels.setDefault(level.zerologLevel())
+}
+
+// zerologLevel converts l to the zerolog level
+func (l LogLevel) zerologLevel() zerolog.Level {
+	switch l {
+	case TraceLevel:
+		return zerolog.TraceLevel
 	case DebugLevel:
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	case InfoLevel:
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		return zerolog.DebugLevel
 	case WarnLevel:
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
+		return zerolog.WarnLevel
 	case ErrorLevel:
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-	case TraceLevel:
-		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+		return zerolog.ErrorLevel
 	default:
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		return zerolog.InfoLevel
 	}
 }
 
-// SetOutput sets the logger output
+// SetOutput sets the logger output, keeping the current format
 func SetOutput(w io.Writer) {
-	output := zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339}
-	output.FormatLevel = func(i interface{}) string {
-		return strings.ToUpper(fmt.Sprintf("| %-6s|", i))
-	}
-	output.FormatMessage = func(i interface{}) string {
-		return fmt.Sprintf("%s", i)
+	SetOutputFormat(w, currentFormat)
+}
+
+// SetOutputFormat sets the logger output and format
+func SetOutputFormat(w io.Writer, format LogFormat) {
+	SetOutputs(Output{Writer: w, Format: format})
+}
+
+// Output is a log destination with its own format
+type Output struct {
+	Writer io.Writer
+	Format LogFormat
+}
+
+// SetOutputs sends every event to all outputs. SetOutput keeps the format
+// of the first one.
+func SetOutputs(outputs ...Output) {
+	writers := make([]io.Writer, len(outputs))
+	for i, o := range outputs {
+		writers[i] = newFormatWriter(o.Writer, o.Format)
 	}
-	output.FormatFieldName = func(i interface{}) string {
-		return fmt.Sprintf("%s:", i)
+	if len(outputs) > 0 {
+		currentFormat = outputs[0].Format
 	}
-	output.FormatFieldValue = func(i interface{}) string {
-		return fmt.Sprintf("%s", i)
+	output.set(zerolog.MultiLevelWriter(writers...))
+}
+
+// swappableWriter writes to a LevelWriter that can be replaced at any time.
+// It discards events until one is set.
+type swappableWriter struct {
+	w atomic.Value // of levelWriterHolder
+}
+
+type levelWriterHolder struct {
+	zerolog.LevelWriter
+}
+
+func (s *swappableWriter) set(w zerolog.LevelWriter) {
+	s.w.Store(levelWriterHolder{w})
+}
+
+func (s *swappableWriter) Write(p []byte) (int, error) {
+	if h, ok := s.w.Load().(levelWriterHolder); ok {
+		return h.Write(p)
 	}
+	return len(p), nil
+}
 
-	log = zerolog.New(output).With().Timestamp().Caller().Logger()
+func (s *swappableWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if h, ok := s.w.Load().(levelWriterHolder); ok {
+		return h.WriteLevel(level, p)
+	}
+	return len(p), nil
 }
 
 // Debug logs a debug message
@@ -51,4 +51,32 @@ func TestLogLevels(t *testing.T) {
 			t.Errorf("Expected log output to contain '%s'", msg)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestSetLevel(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	SetOutput(buffer)
+	defer SetLevel(InfoLevel)
+
+	SetLevel(ErrorLevel)
+	Info().Msg("hidden message")
+	SetLevel(DebugLevel)
+	Debug().Msg("visible message")
+
+	if strings.Contains(buffer.String(), "hidden message") {
+		t.Error("Expected info message to be filtered at error level")
+	}
+	if !strings.Contains(buffer.String(), "visible message") {
+		t.Error("Expected debug message to be logged at debug level")
+	}
+}
+
+func TestLogLevelIsValid(t *testing.T) {
+	for _, level := range []LogLevel{TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		if !level.IsValid() {
+			t.Errorf("Expected %s to be valid", level)
+		}
+	}
+	if LogLevel("verbose").IsValid() {
+		t.Error("Expected 'verbose' to be invalid")
+	}
+}
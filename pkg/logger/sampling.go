@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// levelCounters is the number of counters per sampled level. Messages are
+// hashed onto them, so a collision only makes two messages share a budget.
+const levelCounters = 4096
+
+// SamplingPolicy lets the first Burst events of every Period through, then
+// every Thereafter-th one. With Thereafter 0 the rest of the period is
+// dropped.
+type SamplingPolicy struct {
+	Burst      int
+	Period     time.Duration
+	Thereafter int
+}
+
+// Sampling configures log sampling. An event is counted per level and
+// message and sampled by the policy for its message, else the policy for its
+// level. Events without a policy, and fatal and panic events, are always
+// written.
+type Sampling struct {
+	Levels   map[LogLevel]SamplingPolicy
+	Messages map[string]SamplingPolicy
+}
+
+// sampling holds the active sampler; nil disables sampling
+var sampling atomic.Pointer[sampler]
+
+// dropped counts the events dropped by sampling, indexed by level from
+// trace to error
+var dropped [5]atomic.Uint64
+
+// SetSampling replaces the sampling policies. Counters restart, so every
+// message gets a fresh burst. An empty Sampling disables sampling.
+func SetSampling(s Sampling) error {
+	if len(s.Levels) == 0 && len(s.Messages) == 0 {
+		sampling.Store(nil)
+		return nil
+	}
+
+	smp := &sampler{messages: make(map[string]*messageSampler, len(s.Messages))}
+	for level, policy := range s.Levels {
+		if !level.IsValid() {
+			return fmt.Errorf("invalid log level %q", level)
+		}
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("level %s: %w", level, err)
+		}
+		idx, _ := levelIndex(level.zerologLevel())
+		smp.levels[idx] = &levelSampler{policy: policy}
+	}
+	for msg, policy := range s.Messages {
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("message %q: %w", msg, err)
+		}
+		smp.messages[msg] = &messageSampler{policy: policy}
+	}
+	sampling.Store(smp)
+	return nil
+}
+
+// DroppedEvents returns the number of events dropped by sampling per level
+// since the process started
+func DroppedEvents() map[LogLevel]uint64 {
+	counts := make(map[LogLevel]uint64, len(dropped))
+	for _, level := range []LogLevel{TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		idx, _ := levelIndex(level.zerologLevel())
+		counts[level] = dropped[idx].Load()
+	}
+	return counts
+}
+
+func (p SamplingPolicy) validate() error {
+	switch {
+	case p.Period <= 0:
+		return fmt.Errorf("period must be positive")
+	case p.Burst < 0 || p.Thereafter < 0:
+		return fmt.Errorf("burst and thereafter must not be negative")
+	case p.Burst == 0 && p.Thereafter == 0:
+		return fmt.Errorf("burst or thereafter must be positive")
+	}
+	return nil
+}
+
+// levelIndex maps the sampled levels trace to error onto 0-4
+func levelIndex(level zerolog.Level) (int, bool) {
+	idx := int(level) - int(zerolog.TraceLevel)
+	return idx, idx >= 0 && idx < len(dropped)
+}
+
+// sampler decides which events are written. It is immutable after
+// SetSampling except for the counters.
+type sampler struct {
+	levels   [5]*levelSampler
+	messages map[string]*messageSampler
+}
+
+type levelSampler struct {
+	policy   SamplingPolicy
+	counters [levelCounters]sampleCounter
+}
+
+type messageSampler struct {
+	policy  SamplingPolicy
+	counter sampleCounter
+}
+
+// keep reports whether an event passes sampling and counts it as dropped
+// when it does not
+func (s *sampler) keep(level zerolog.Level, msg string, now time.Time) bool {
+	idx, ok := levelIndex(level)
+	if !ok {
+		return true
+	}
+
+	var (
+		policy  SamplingPolicy
+		counter *sampleCounter
+	)
+	if ms := s.messages[msg]; ms != nil {
+		policy, counter = ms.policy, &ms.counter
+	} else if ls := s.levels[idx]; ls != nil {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(msg))
+		policy, counter = ls.policy, &ls.counters[h.Sum32()%levelCounters]
+	} else {
+		return true
+	}
+
+	if counter.keep(policy, now) {
+		return true
+	}
+	dropped[idx].Add(1)
+	return false
+}
+
+// sampleCounter counts the events of one period
+type sampleCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// keep counts an event and reports whether policy lets it through
+func (c *sampleCounter) keep(policy SamplingPolicy, now time.Time) bool {
+	n := c.inc(policy.Period, now)
+	burst := uint64(policy.Burst)
+	if n <= burst {
+		return true
+	}
+	return policy.Thereafter > 0 && (n-burst)%uint64(policy.Thereafter) == 0
+}
+
+// inc counts an event and returns its position in the current period,
+// starting a new period once the previous one has ended
+func (c *sampleCounter) inc(period time.Duration, now time.Time) uint64 {
+	tn := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if tn < resetAt {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, tn+period.Nanoseconds()) {
+		// Another event started the period
+		return c.count.Add(1)
+	}
+	return 1
+}
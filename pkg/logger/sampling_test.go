@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplingCounter(t *testing.T) {
+	policy := SamplingPolicy{Burst: 3, Period: time.Second, Thereafter: 5}
+	now := time.Now()
+
+	var c sampleCounter
+	var kept []int
+	for i := 1; i <= 20; i++ {
+		if c.keep(policy, now) {
+			kept = append(kept, i)
+		}
+	}
+	// The burst, then every 5th event after it
+	want := []int{1, 2, 3, 8, 13, 18}
+	if len(kept) != len(want) {
+		t.Fatalf("Expected events %v to be kept, got %v", want, kept)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Fatalf("Expected events %v to be kept, got %v", want, kept)
+		}
+	}
+
+	// A new period starts a new burst
+	if !c.keep(policy, now.Add(time.Second)) {
+		t.Error("Expected the first event of a new period to be kept")
+	}
+}
+
+func TestSamplingDropsOnlyPastBurst(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling Sampling
+		log      func()
+		written  int
+		dropped  uint64
+	}{
+		{
+			name:     "level policy",
+			sampling: Sampling{Levels: map[LogLevel]SamplingPolicy{InfoLevel: {Burst: 2, Period: time.Hour}}},
+			log: func() {
+				for i := 0; i < 5; i++ {
+					Info().Msg("hot path")
+				}
+				// Other messages and levels have their own budget
+				Info().Msg("other message")
+				Warn().Msg("hot path")
+			},
+			written: 4,
+			dropped: 3,
+		},
+		{
+			name: "message policy overrides level policy",
+			sampling: Sampling{
+				Levels:   map[LogLevel]SamplingPolicy{InfoLevel: {Burst: 1, Period: time.Hour}},
+				Messages: map[string]SamplingPolicy{"hot path": {Burst: 3, Period: time.Hour}},
+			},
+			log: func() {
+				for i := 0; i < 5; i++ {
+					Info().Msg("hot path")
+					Info().Msg("other message")
+				}
+			},
+			written: 4,
+			dropped: 6,
+		},
+		{
+			name:     "component loggers",
+			sampling: Sampling{Messages: map[string]SamplingPolicy{"hot path": {Burst: 1, Period: time.Hour}}},
+			log: func() {
+				Component("sampling-test").Error().Msg("hot path")
+				Component("sampling-test").Error().Msg("hot path")
+			},
+			written: 1,
+			dropped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			SetOutputFormat(buf, JSONFormat)
+			if err := SetSampling(tt.sampling); err != nil {
+				t.Fatalf("Failed to set sampling: %v", err)
+			}
+			defer SetSampling(Sampling{})
+
+			before := DroppedEvents()
+			tt.log()
+
+			written := strings.Count(buf.String(), "\n")
+			if written != tt.written {
+				t.Errorf("Expected %d lines, got %d:\n%s", tt.written, written, buf.String())
+			}
+
+			var droppedNow uint64
+			for level, count := range DroppedEvents() {
+				droppedNow += count - before[level]
+			}
+			if droppedNow != tt.dropped {
+				t.Errorf("Expected %d dropped events, got %d", tt.dropped, droppedNow)
+			}
+		})
+	}
+}
+
+func TestSetSamplingValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling Sampling
+	}{
+		{"invalid level", Sampling{Levels: map[LogLevel]SamplingPolicy{"loud": {Burst: 1, Period: time.Second}}}},
+		{"missing period", Sampling{Levels: map[LogLevel]SamplingPolicy{InfoLevel: {Burst: 1}}}},
+		{"drops everything", Sampling{Messages: map[string]SamplingPolicy{"msg": {Period: time.Second}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetSampling(tt.sampling); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
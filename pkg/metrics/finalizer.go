@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Finalizer metrics, labelled by controller and finalizer name
+var (
+	finalizerCleanupTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "finalizer_cleanup_total",
+		Help:      "Total number of finalizer cleanups per controller, finalizer and result (success, error).",
+	}, []string{"controller", "finalizer", "result"})
+
+	finalizerPendingObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "finalizer_pending_objects",
+		Help:      "Number of deleted objects whose finalizer cleanup failed and is being retried.",
+	}, []string{"controller", "finalizer"})
+
+	finalizerOldestPending = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "finalizer_oldest_pending_deletion_timestamp_seconds",
+		Help:      "Deletion timestamp of the oldest object whose finalizer cleanup is being retried, or 0 when there is none.",
+	}, []string{"controller", "finalizer"})
+)
+
+func init() {
+	Registry.MustRegister(
+		finalizerCleanupTotal,
+		finalizerPendingObjects,
+		finalizerOldestPending,
+	)
+}
+
+// ObserveFinalizerCleanup records a cleanup attempt; result is success or
+// error
+func ObserveFinalizerCleanup(controller, finalizer, result string) {
+	finalizerCleanupTotal.WithLabelValues(controller, finalizer, result).Inc()
+}
+
+// SetFinalizerPending records the number of objects whose cleanup is being
+// retried and the deletion timestamp of the oldest one. Alerting on
+// time() - oldest finds finalizers that are stuck.
+func SetFinalizerPending(controller, finalizer string, pending int, oldest time.Time) {
+	finalizerPendingObjects.WithLabelValues(controller, finalizer).Set(float64(pending))
+	var timestamp float64
+	if pending > 0 {
+		timestamp = float64(oldest.Unix())
+	}
+	finalizerOldestPending.WithLabelValues(controller, finalizer).Set(timestamp)
+}
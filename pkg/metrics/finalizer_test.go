@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveFinalizerCleanup(t *testing.T) {
+	failures := testutil.ToFloat64(finalizerCleanupTotal.WithLabelValues("test", "example.com/cleanup", "error"))
+	successes := testutil.ToFloat64(finalizerCleanupTotal.WithLabelValues("test", "example.com/cleanup", "success"))
+
+	ObserveFinalizerCleanup("test", "example.com/cleanup", "error")
+	ObserveFinalizerCleanup("test", "example.com/cleanup", "error")
+	ObserveFinalizerCleanup("test", "example.com/cleanup", "success")
+
+	if got := testutil.ToFloat64(finalizerCleanupTotal.WithLabelValues("test", "example.com/cleanup", "error")) - failures; got != 2 {
+		t.Errorf("Expected 2 failed cleanups, got %v", got)
+	}
+	if got := testutil.ToFloat64(finalizerCleanupTotal.WithLabelValues("test", "example.com/cleanup", "success")) - successes; got != 1 {
+		t.Errorf("Expected 1 successful cleanup, got %v", got)
+	}
+}
+
+func TestSetFinalizerPending(t *testing.T) {
+	oldest := time.Unix(1700000000, 0)
+	SetFinalizerPending("test", "example.com/cleanup", 2, oldest)
+
+	if got := testutil.ToFloat64(finalizerPendingObjects.WithLabelValues("test", "example.com/cleanup")); got != 2 {
+		t.Errorf("Expected 2 pending objects, got %v", got)
+	}
+	if got := testutil.ToFloat64(finalizerOldestPending.WithLabelValues("test", "example.com/cleanup")); got != 1700000000 {
+		t.Errorf("Expected the oldest deletion timestamp, got %v", got)
+	}
+
+	SetFinalizerPending("test", "example.com/cleanup", 0, oldest)
+	if got := testutil.ToFloat64(finalizerOldestPending.WithLabelValues("test", "example.com/cleanup")); got != 0 {
+		t.Errorf("Expected no timestamp without pending objects, got %v", got)
+	}
+}
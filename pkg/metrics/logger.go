@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"k8s-controller/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// logDroppedDesc describes the log events dropped by sampling per level
+var logDroppedDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "log", "sampled_dropped_total"),
+	"Total number of log events dropped by sampling per level.",
+	[]string{"level"}, nil,
+)
+
+// logCollector exposes the dropped event counts kept by the logger package,
+// so the logger does not depend on Prometheus
+type logCollector struct{}
+
+func (logCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- logDroppedDesc
+}
+
+func (logCollector) Collect(ch chan<- prometheus.Metric) {
+	for level, count := range logger.DroppedEvents() {
+		ch <- prometheus.MustNewConstMetric(logDroppedDesc, prometheus.CounterValue, float64(count), string(level))
+	}
+}
+
+func init() {
+	Registry.MustRegister(logCollector{})
+}
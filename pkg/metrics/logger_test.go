@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-controller/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLogDroppedMetric(t *testing.T) {
+	logger.SetOutput(io.Discard)
+	err := logger.SetSampling(logger.Sampling{
+		Messages: map[string]logger.SamplingPolicy{"metrics test": {Burst: 1, Period: time.Hour}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set sampling: %v", err)
+	}
+	defer logger.SetSampling(logger.Sampling{})
+
+	before := logger.DroppedEvents()
+	for i := 0; i < 4; i++ {
+		logger.Warn().Msg("metrics test")
+	}
+	after := logger.DroppedEvents()
+
+	// Counts are kept for the whole process, so compare against the start
+	if got := after[logger.WarnLevel] - before[logger.WarnLevel]; got != 3 {
+		t.Errorf("Expected 3 dropped warn events, got %d", got)
+	}
+
+	expected := strings.NewReader(fmt.Sprintf(`
+# HELP k8s_controller_log_sampled_dropped_total Total number of log events dropped by sampling per level.
+# TYPE k8s_controller_log_sampled_dropped_total counter
+k8s_controller_log_sampled_dropped_total{level="debug"} %d
+k8s_controller_log_sampled_dropped_total{level="error"} %d
+k8s_controller_log_sampled_dropped_total{level="info"} %d
+k8s_controller_log_sampled_dropped_total{level="trace"} %d
+k8s_controller_log_sampled_dropped_total{level="warn"} %d
+`, after[logger.DebugLevel], after[logger.ErrorLevel], after[logger.InfoLevel], after[logger.TraceLevel], after[logger.WarnLevel]))
+	if err := testutil.CollectAndCompare(logCollector{}, expected); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// namespace prefixes every metric exposed by the application
+const namespace = "k8s_controller"
+
+// Registry holds every application metric plus the Go runtime and process
+// collectors. It is served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by method, path and status code.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency in seconds by method, path and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "reconcile_total",
+		Help:      "Total number of reconciles per controller and result (success, error, requeue, requeue_after).",
+	}, []string{"controller", "result"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "reconcile_errors_total",
+		Help:      "Total number of reconcile errors per controller.",
+	}, []string{"controller"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time spent in a single reconcile per controller.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"controller"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		reconcileTotal,
+		reconcileErrors,
+		reconcileDuration,
+	)
+}
+
+// Handler serves the Prometheus text format for Registry
+func Handler() fasthttp.RequestHandler {
+	return fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// ObserveHTTPRequest records a completed HTTP request
+func ObserveHTTPRequest(method, path string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequestsTotal.WithLabelValues(method, path, code).Inc()
+	httpRequestDuration.WithLabelValues(method, path, code).Observe(duration.Seconds())
+}
+
+// ObserveReconcile records a completed reconcile; result is one of success,
+// error, requeue or requeue_after
+func ObserveReconcile(controller, result string, duration time.Duration) {
+	reconcileTotal.WithLabelValues(controller, result).Inc()
+	reconcileDuration.WithLabelValues(controller).Observe(duration.Seconds())
+	if result == "error" {
+		reconcileErrors.WithLabelValues(controller).Inc()
+	}
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/valyala/fasthttp"
+)
+
+func TestObserveHTTPRequest(t *testing.T) {
+	before := testutil.ToFloat64(httpRequestsTotal.WithLabelValues("GET", "/test", "200"))
+
+	ObserveHTTPRequest("GET", "/test", 200, 10*time.Millisecond)
+
+	after := testutil.ToFloat64(httpRequestsTotal.WithLabelValues("GET", "/test", "200"))
+	if after-before != 1 {
+		t.Errorf("Expected request counter to increase by 1, got %v", after-before)
+	}
+}
+
+func TestObserveReconcile(t *testing.T) {
+	successes := testutil.ToFloat64(reconcileTotal.WithLabelValues("test", "success"))
+	failures := testutil.ToFloat64(reconcileTotal.WithLabelValues("test", "error"))
+	errors := testutil.ToFloat64(reconcileErrors.WithLabelValues("test"))
+
+	ObserveReconcile("test", "success", time.Millisecond)
+	ObserveReconcile("test", "error", time.Millisecond)
+	ObserveReconcile("test", "error", time.Millisecond)
+
+	if got := testutil.ToFloat64(reconcileTotal.WithLabelValues("test", "success")) - successes; got != 1 {
+		t.Errorf("Expected 1 successful reconcile, got %v", got)
+	}
+	if got := testutil.ToFloat64(reconcileTotal.WithLabelValues("test", "error")) - failures; got != 2 {
+		t.Errorf("Expected 2 failed reconciles, got %v", got)
+	}
+	if got := testutil.ToFloat64(reconcileErrors.WithLabelValues("test")) - errors; got != 2 {
+		t.Errorf("Expected 2 reconcile errors, got %v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveHTTPRequest("GET", "/handler-test", 200, time.Millisecond)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/metrics")
+	Handler()(ctx)
+
+	if ctx.Response.StatusCode() != 200 {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+
+	body := string(ctx.Response.Body())
+	expected := []string{
+		`k8s_controller_http_requests_total{method="GET",path="/handler-test",status="200"}`,
+		"k8s_controller_http_request_duration_seconds_bucket",
+		"go_goroutines",
+	}
+	for _, metric := range expected {
+		if !strings.Contains(body, metric) {
+			t.Errorf("Expected metrics output to contain %q", metric)
+		}
+	}
+}
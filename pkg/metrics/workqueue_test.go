@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestWorkqueueMetrics(t *testing.T) {
+	queue := workqueue.NewTypedRateLimitingQueueWithConfig(
+		workqueue.DefaultTypedControllerRateLimiter[string](),
+		workqueue.TypedRateLimitingQueueConfig[string]{Name: "metrics-test"},
+	)
+	defer queue.ShutDown()
+
+	// The registry is global, so compare against the values before this run
+	depth := testutil.ToFloat64(workqueueDepth.WithLabelValues("metrics-test"))
+	adds := testutil.ToFloat64(workqueueAdds.WithLabelValues("metrics-test"))
+	retries := testutil.ToFloat64(workqueueRetries.WithLabelValues("metrics-test"))
+
+	queue.Add("a")
+	queue.Add("b")
+
+	if got := testutil.ToFloat64(workqueueDepth.WithLabelValues("metrics-test")) - depth; got != 2 {
+		t.Errorf("Expected depth 2, got %v", got)
+	}
+	if got := testutil.ToFloat64(workqueueAdds.WithLabelValues("metrics-test")) - adds; got != 2 {
+		t.Errorf("Expected 2 adds, got %v", got)
+	}
+
+	item, _ := queue.Get()
+	queue.AddRateLimited(item)
+	queue.Done(item)
+
+	if got := testutil.ToFloat64(workqueueRetries.WithLabelValues("metrics-test")) - retries; got != 1 {
+		t.Errorf("Expected 1 retry, got %v", got)
+	}
+	if got := testutil.ToFloat64(workqueueDepth.WithLabelValues("metrics-test")) - depth; got != 1 {
+		t.Errorf("Expected depth 1 after Get, got %v", got)
+	}
+}
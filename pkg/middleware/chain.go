@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Middleware wraps a handler with extra behaviour
+type Middleware func(fasthttp.RequestHandler) fasthttp.RequestHandler
+
+// Chain composes middlewares into one. The first middleware is the
+// outermost, so Chain(a, b)(h) handles a request as a(b(h)).
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// routeKey is the user value key under which the matched route is stored
+type routeKey struct{}
+
+// SetRoute records the route pattern that matched the request, e.g.
+// "/api/items/:id". Routers call it so that metrics and spans are labelled
+// by route instead of by the raw path.
+func SetRoute(ctx *fasthttp.RequestCtx, pattern string) {
+	ctx.SetUserValue(routeKey{}, pattern)
+}
+
+// RouteFromContext returns the route pattern recorded by SetRoute, or ""
+// when no route matched
+func RouteFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return func(ctx *fasthttp.RequestCtx) {
+				calls = append(calls, name+" before")
+				next(ctx)
+				calls = append(calls, name+" after")
+			}
+		}
+	}
+
+	handler := Chain(record("outer"), Chain(record("middle")), record("inner"))(func(ctx *fasthttp.RequestCtx) {
+		calls = append(calls, "handler")
+	})
+	handler(&fasthttp.RequestCtx{})
+
+	expected := []string{"outer before", "middle before", "inner before", "handler", "inner after", "middle after", "outer after"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestChainEmpty(t *testing.T) {
+	called := false
+	Chain()(func(ctx *fasthttp.RequestCtx) { called = true })(&fasthttp.RequestCtx{})
+	if !called {
+		t.Error("Expected an empty chain to call the handler")
+	}
+}
+
+func TestRouteFromContext(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	if got := RouteFromContext(ctx); got != "" {
+		t.Errorf("Expected no route before SetRoute, got %q", got)
+	}
+
+	SetRoute(ctx, "/api/items/:id")
+	if got := RouteFromContext(ctx); got != "/api/items/:id" {
+		t.Errorf("Expected route /api/items/:id, got %q", got)
+	}
+	if got := RouteFromContext(context.Background()); got != "" {
+		t.Errorf("Expected no route in a plain context, got %q", got)
+	}
+}
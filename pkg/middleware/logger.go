@@ -1,8 +1,9 @@
 package middleware
 
 import (
-	"fmt"
+	"k8s-controller/pkg/config"
 	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/tracing"
 	"time"
 
 	"github.com/valyala/fasthttp"
@@ -10,22 +11,25 @@ import (
 
 // RequestLogger is a middleware that logs HTTP requests with detailed information
 func RequestLogger(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	httpLog := logger.Component("http")
+	
 	return func(ctx *fasthttp.RequestCtx) {
 		// Record start time
 		start := time.Now()
 		
-		// Create request ID for tracing
-		requestID := fmt.Sprintf("%016X", start.UnixNano())
+		// Use the request ID for tracing in a logger scoped to the request
+		requestID := ensureRequestID(ctx)
+		reqLogger := tracing.WithTraceFields(ctx, httpLog.With().Str("request_id", requestID)).Logger()
+		logger.IntoContext(ctx, &reqLogger)
 		
 		// Get client IP address
 		clientIP := ctx.RemoteIP().String()
 		
 		// Log request details before handling
-		logger.Debug().
-			Str("request_id", requestID).
+		reqLogger.Debug().
 			Str("client_ip", clientIP).
 			Str("method", string(ctx.Method())).
-			Str("uri", string(ctx.RequestURI())).
+			Str("uri", redactURI(string(ctx.RequestURI()), config.DefaultRedactKeys)).
 			Msg("Request received")
 		
 		// Process request
@@ -42,17 +46,16 @@ func RequestLogger(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		userAgent := string(ctx.UserAgent())
 		
 		// Log the completed request
-		logEvent := logger.Info()
+		logEvent := reqLogger.Info()
 		
 		// For errors (4xx, 5xx), use higher log level
 		if statusCode >= 400 && statusCode < 500 {
-			logEvent = logger.Warn()
+			logEvent = reqLogger.Warn()
 		} else if statusCode >= 500 {
-			logEvent = logger.Error()
+			logEvent = reqLogger.Error()
 		}
 		
 		logEvent.
-			Str("request_id", requestID).
 			Str("client_ip", clientIP).
 			Str("method", method).
 			Str("path", path).
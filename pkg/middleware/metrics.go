@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"k8s-controller/pkg/metrics"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// unmatchedPath is the path label used for requests that matched no route,
+// so scanners probing random URLs cannot blow up metric cardinality
+const unmatchedPath = "unmatched"
+
+// RequestMetrics is a middleware that records request counts and latencies
+// by method, path and status code. Requests matched by a router are
+// labelled with the route pattern.
+func RequestMetrics(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+
+		next(ctx)
+
+		statusCode := ctx.Response.StatusCode()
+		path := RouteFromContext(ctx)
+		switch {
+		case path != "":
+		case statusCode == fasthttp.StatusNotFound:
+			path = unmatchedPath
+		default:
+			path = string(ctx.Path())
+		}
+
+		metrics.ObserveHTTPRequest(string(ctx.Method()), path, statusCode, time.Since(start))
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-controller/pkg/metrics"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	// Handler that knows a single route
+	testHandler := func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == "/known" {
+			ctx.SetStatusCode(200)
+			return
+		}
+		ctx.SetStatusCode(404)
+	}
+	handler := RequestMetrics(testHandler)
+	known := requestCount(t, "POST", "/known", "200")
+	unmatched := requestCount(t, "POST", "unmatched", "404")
+
+	for _, path := range []string{"/known", "/random-1", "/random-2"} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("http://localhost" + path)
+		ctx.Request.Header.SetMethod("POST")
+		handler(ctx)
+	}
+
+	// Read the exposition output
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/metrics")
+	metrics.Handler()(ctx)
+	body := string(ctx.Response.Body())
+
+	if got := requestCount(t, "POST", "/known", "200") - known; got != 1 {
+		t.Errorf("Expected 1 request counted under /known, got %v", got)
+	}
+	if got := requestCount(t, "POST", "unmatched", "404") - unmatched; got != 2 {
+		t.Errorf("Expected 2 requests counted as unmatched, got %v", got)
+	}
+	if strings.Contains(body, "/random-1") {
+		t.Error("Expected unmatched paths not to be used as label values")
+	}
+}
+
+func TestRequestMetricsUsesRoute(t *testing.T) {
+	handler := RequestMetrics(func(ctx *fasthttp.RequestCtx) {
+		SetRoute(ctx, "/things/:id")
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	})
+	before := requestCount(t, "GET", "/things/:id", "404")
+
+	for _, path := range []string{"/things/1", "/things/2"} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("http://localhost" + path)
+		handler(ctx)
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/metrics")
+	metrics.Handler()(ctx)
+	body := string(ctx.Response.Body())
+
+	// A route that matched but found no item still counts under its pattern
+	if got := requestCount(t, "GET", "/things/:id", "404") - before; got != 2 {
+		t.Errorf("Expected 2 requests counted under /things/:id, got %v", got)
+	}
+	if strings.Contains(body, "/things/1") {
+		t.Error("Expected requests matched by a route not to be labelled by path")
+	}
+}
+
+// requestCount returns the request counter for the given labels from the
+// global registry; tests compare it before and after so they can run twice
+func requestCount(t *testing.T, method, path, status string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	expected := map[string]string{"method": method, "path": path, "status": status}
+	for _, family := range families {
+		if family.GetName() != "k8s_controller_http_requests_total" {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if expected[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// redacted replaces secret values in request logs
+const redacted = "<redacted>"
+
+// matchAny reports whether name matches one of the case-insensitive glob
+// patterns (see path.Match). Invalid patterns never match.
+func matchAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// orDefault returns patterns, or defaults when patterns is nil. An empty
+// non-nil list disables redaction.
+func orDefault(patterns, defaults []string) []string {
+	if patterns == nil {
+		return defaults
+	}
+	return patterns
+}
+
+// redactURI masks the values of the query parameters of uri matching
+// patterns, keeping the order and encoding of the others
+func redactURI(uri string, patterns []string) string {
+	base, query, found := strings.Cut(uri, "?")
+	if !found {
+		return uri
+	}
+	return base + "?" + redactQuery(query, patterns)
+}
+
+// redactQuery masks the values of the keys of a urlencoded query or form
+// matching patterns, keeping the order and encoding of the others
+func redactQuery(query string, patterns []string) string {
+	if len(patterns) == 0 {
+		return query
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		rawKey, _, hasValue := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if hasValue && matchAny(patterns, key) {
+			params[i] = rawKey + "=" + redacted
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// redactBody masks the values of keys matching patterns in a form body, as
+// told by contentType, or in a JSON one
+func redactBody(body []byte, contentType string, patterns []string) []byte {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+		return []byte(redactQuery(string(body), patterns))
+	}
+	return redactJSON(body, patterns)
+}
+
+// redactJSON masks the values of object keys matching patterns at any depth.
+// Bodies that are not JSON are returned unchanged.
+func redactJSON(body []byte, patterns []string) []byte {
+	if len(patterns) == 0 {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil || decoder.More() {
+		return body
+	}
+	if !redactValue(doc, patterns) {
+		return body
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return body
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// redactValue masks matching keys in v and reports whether it changed
+func redactValue(v interface{}, patterns []string) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if matchAny(patterns, key) {
+				v[key] = redacted
+				changed = true
+				continue
+			}
+			changed = redactValue(value, patterns) || changed
+		}
+	case []interface{}:
+		for _, value := range v {
+			changed = redactValue(value, patterns) || changed
+		}
+	}
+	return changed
+}
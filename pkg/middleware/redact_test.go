@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"testing"
+
+	"k8s-controller/pkg/config"
+)
+
+func TestRedactURI(t *testing.T) {
+	tests := []struct {
+		name     string
+		uri      string
+		patterns []string
+		expected string
+	}{
+		{
+			name:     "no query",
+			uri:      "/api/users",
+			patterns: config.DefaultRedactKeys,
+			expected: "/api/users",
+		},
+		{
+			name:     "default keys",
+			uri:      "/api?user=bob&access_token=abc&Api_Key=xyz&page=2",
+			patterns: config.DefaultRedactKeys,
+			expected: "/api?user=bob&access_token=<redacted>&Api_Key=<redacted>&page=2",
+		},
+		{
+			name:     "escaped key",
+			uri:      "/api?%74oken=abc&flag",
+			patterns: []string{"token"},
+			expected: "/api?%74oken=<redacted>&flag",
+		},
+		{
+			name:     "redaction disabled",
+			uri:      "/api?token=abc",
+			patterns: []string{},
+			expected: "/api?token=abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactURI(tt.uri, tt.patterns); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		expected    string
+	}{
+		{
+			name:        "form",
+			body:        "user=bob&password=hunter2&access%5Ftoken=abc",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			expected:    "user=bob&password=<redacted>&access%5Ftoken=<redacted>",
+		},
+		{
+			name:        "json",
+			body:        `{"password":"hunter2"}`,
+			contentType: "application/json",
+			expected:    `{"password":"<redacted>"}`,
+		},
+		{
+			name:        "form without content type",
+			body:        "password=hunter2",
+			contentType: "",
+			expected:    "password=hunter2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactBody([]byte(tt.body), tt.contentType, config.DefaultRedactKeys)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "nested keys",
+			body:     `{"user":"bob","Password":"hunter2","auth":{"refresh_token":"abc","ttl":3600},"items":[{"client_secret":"s"}]}`,
+			expected: `{"Password":"<redacted>","auth":{"refresh_token":"<redacted>","ttl":3600},"items":[{"client_secret":"<redacted>"}],"user":"bob"}`,
+		},
+		{
+			name:     "nothing to redact",
+			body:     `{"b": 1, "a": 2}`,
+			expected: `{"b": 1, "a": 2}`,
+		},
+		{
+			name:     "not json",
+			body:     "password=hunter2",
+			expected: "password=hunter2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactJSON([]byte(tt.body), config.DefaultRedactKeys)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	if !matchAny(config.DefaultRedactHeaders, "authorization") {
+		t.Error("Expected header names to match case-insensitively")
+	}
+	if matchAny([]string{"[bad"}, "[bad") {
+		t.Error("Expected invalid patterns not to match")
+	}
+}
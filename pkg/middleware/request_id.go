@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"k8s-controller/pkg/tracing"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// RequestIDHeader carries the request ID in requests and responses
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader is the W3C Trace Context header
+	TraceparentHeader = "traceparent"
+
+	// maxRequestIDLength bounds incoming request IDs
+	maxRequestIDLength = 128
+)
+
+// requestIDKey is the user value key under which the request ID is stored
+type requestIDKey struct{}
+
+// RequestID is a middleware that assigns every request an ID. An incoming
+// X-Request-ID header is used when it is valid, else the trace ID of a W3C
+// traceparent header or of the span started by Tracing, else a new random
+// ID. The ID is echoed in the X-Request-ID response header and stored on the
+// RequestCtx, where RequestIDFromContext and the request loggers find it.
+func RequestID(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ensureRequestID(ctx)
+		next(ctx)
+	}
+}
+
+// RequestIDFromContext returns the request ID assigned by RequestID or a
+// request logger, or "" when there is none
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 128-bit ID as 32 hex characters, the format
+// of a W3C trace ID
+func NewRequestID() string {
+	var id [16]byte
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// ensureRequestID returns the ID of the request, assigning one first if no
+// middleware did yet
+func ensureRequestID(ctx *fasthttp.RequestCtx) string {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return id
+	}
+
+	id := string(ctx.Request.Header.Peek(RequestIDHeader))
+	if !validRequestID(id) {
+		id = traceIDFromTraceparent(string(ctx.Request.Header.Peek(TraceparentHeader)))
+	}
+	if sc := tracing.SpanFromContext(ctx).SpanContext(); id == "" && sc.IsValid() {
+		// Behind the Tracing middleware the request ID is the trace ID
+		id = sc.TraceID().String()
+	}
+	if id == "" {
+		id = NewRequestID()
+	}
+
+	ctx.SetUserValue(requestIDKey{}, id)
+	ctx.Response.Header.Set(RequestIDHeader, id)
+	return id
+}
+
+// validRequestID accepts IDs of up to 128 letters, digits and the
+// punctuation used by common ID formats, so that client supplied IDs
+// cannot inject anything into logs or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-_.:+/=@", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// traceIDFromTraceparent returns the trace ID of a W3C traceparent header
+// ("00-<trace-id>-<parent-id>-<flags>"), or "" when it is invalid
+func traceIDFromTraceparent(header string) string {
+	header = strings.TrimSpace(header)
+	if len(header) < 55 || (len(header) > 55 && header[55] != '-') {
+		return ""
+	}
+	version, traceID, parentID, flags := header[0:2], header[3:35], header[36:52], header[53:55]
+	if header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return ""
+	}
+	// Version 00 has exactly four fields; ff is forbidden
+	if version == "ff" || (version == "00" && len(header) != 55) {
+		return ""
+	}
+	for _, field := range []string{version, traceID, parentID, flags} {
+		if !isLowerHex(field) {
+			return ""
+		}
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(parentID, "0") == "" {
+		return ""
+	}
+	return traceID
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s-controller/pkg/logger"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "incoming request ID",
+			headers:  map[string]string{RequestIDHeader: "abc-123"},
+			expected: "abc-123",
+		},
+		{
+			name: "request ID wins over traceparent",
+			headers: map[string]string{
+				RequestIDHeader:   "abc-123",
+				TraceparentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			expected: "abc-123",
+		},
+		{
+			name:     "traceparent",
+			headers:  map[string]string{TraceparentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			expected: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "invalid request ID falls back to traceparent",
+			headers: map[string]string{
+				RequestIDHeader:   "bad id\n",
+				TraceparentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			expected: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:    "invalid traceparent",
+			headers: map[string]string{TraceparentHeader: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		},
+		{
+			name:    "no headers",
+			headers: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := RequestID(func(ctx *fasthttp.RequestCtx) {
+				seen = RequestIDFromContext(ctx)
+			})
+
+			ctx := &fasthttp.RequestCtx{}
+			for key, value := range tt.headers {
+				ctx.Request.Header.Set(key, value)
+			}
+			handler(ctx)
+
+			if tt.expected != "" && seen != tt.expected {
+				t.Errorf("Expected request ID %q, got %q", tt.expected, seen)
+			}
+			if tt.expected == "" && (len(seen) != 32 || !isLowerHex(seen)) {
+				t.Errorf("Expected a generated 32 hex character ID, got %q", seen)
+			}
+			if echoed := string(ctx.Response.Header.Peek(RequestIDHeader)); echoed != seen {
+				t.Errorf("Expected the response to echo %q, got %q", seen, echoed)
+			}
+		})
+	}
+}
+
+func TestTraceIDFromTraceparent(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", ""},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := traceIDFromTraceparent(tt.header); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewRequestIDUnique(t *testing.T) {
+	const goroutines, perGoroutine = 8, 1000
+
+	var mu sync.Mutex
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				id := NewRequestID()
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("Duplicate request ID %s", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRequestLoggersUseRequestID(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutputFormat(buffer, logger.JSONFormat)
+	defer logger.SetOutputFormat(new(bytes.Buffer), logger.ConsoleFormat)
+
+	noop := func(ctx *fasthttp.RequestCtx) {}
+	handlers := map[string]fasthttp.RequestHandler{
+		"RequestLogger":                   RequestLogger(noop),
+		"EnhancedRequestLogger":           EnhancedRequestLogger(nil)(noop),
+		"RequestID before RequestLogger":  RequestID(RequestLogger(noop)),
+		"RequestID before EnhancedLogger": RequestID(EnhancedRequestLogger(nil)(noop)),
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			buffer.Reset()
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.Set(RequestIDHeader, "incoming-id")
+			handler(ctx)
+
+			if !strings.Contains(buffer.String(), `"request_id":"incoming-id"`) {
+				t.Errorf("Expected the incoming request ID to be logged, got %s", buffer.String())
+			}
+			if got := string(ctx.Response.Header.Peek(RequestIDHeader)); got != "incoming-id" {
+				t.Errorf("Expected the request ID to be echoed, got %q", got)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package middleware
 
 import (
-	"fmt"
+	"k8s-controller/pkg/config"
 	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/tracing"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -24,30 +26,92 @@ type LoggingOptions struct {
 	MaxBodyLogSize int
 	// LogTiming enables detailed timing information
 	LogTiming bool
+	// RedactHeaders lists header name patterns whose values are masked;
+	// nil selects config.DefaultRedactHeaders
+	RedactHeaders []string
+	// RedactBodyKeys lists JSON and form body key patterns whose values are
+	// masked; nil selects config.DefaultRedactKeys
+	RedactBodyKeys []string
+	// RedactQueryParams lists query parameter patterns whose values are
+	// masked in the logged uri; nil selects config.DefaultRedactKeys
+	RedactQueryParams []string
 }
 
 // DefaultLoggingOptions returns default logging options
 func DefaultLoggingOptions() *LoggingOptions {
 	return &LoggingOptions{
-		SkipPaths:       []string{"/health", "/metrics"},
-		LogHeaders:      false,
-		LogRequestBody:  false,
-		LogResponseBody: false,
-		MaxBodyLogSize:  1024, // 1KB max for body logging
-		LogTiming:       true,
+		SkipPaths:         []string{"/health", "/readyz", "/metrics"},
+		LogHeaders:        false,
+		LogRequestBody:    false,
+		LogResponseBody:   false,
+		MaxBodyLogSize:    1024, // 1KB max for body logging
+		LogTiming:         true,
+		RedactHeaders:     config.DefaultRedactHeaders,
+		RedactBodyKeys:    config.DefaultRedactKeys,
+		RedactQueryParams: config.DefaultRedactKeys,
 	}
 }
 
+// ReloadableLoggingOptions holds LoggingOptions that can be swapped while
+// requests are being served
+type ReloadableLoggingOptions struct {
+	options atomic.Pointer[LoggingOptions]
+}
+
+// NewReloadableLoggingOptions returns a holder initialized with options
+// (DefaultLoggingOptions when nil)
+func NewReloadableLoggingOptions(options *LoggingOptions) *ReloadableLoggingOptions {
+	r := &ReloadableLoggingOptions{}
+	r.Store(options)
+	return r
+}
+
+// Load returns the current options
+func (r *ReloadableLoggingOptions) Load() *LoggingOptions {
+	return r.options.Load()
+}
+
+// Store replaces the options used by subsequent requests
+func (r *ReloadableLoggingOptions) Store(options *LoggingOptions) {
+	if options == nil {
+		options = DefaultLoggingOptions()
+	}
+	r.options.Store(options)
+}
+
 // EnhancedRequestLogger creates a middleware with configurable options
 func EnhancedRequestLogger(options *LoggingOptions) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
 	if options == nil {
 		options = DefaultLoggingOptions()
 	}
+	return requestLogger(func() *LoggingOptions { return options })
+}
+
+// ReloadableRequestLogger creates a middleware that reads its options from
+// options on every request, so they can change without rebuilding handlers
+func ReloadableRequestLogger(options *ReloadableLoggingOptions) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return requestLogger(options.Load)
+}
+
+// requestLogger creates the request logging middleware; load returns the
+// options applied to each request
+func requestLogger(load func() *LoggingOptions) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	httpLog := logger.Component("http")
 	
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
+			options := load()
 			path := string(ctx.Path())
 			
+			// Record start time
+			start := time.Now()
+			
+			// Use the request ID for tracing in a logger scoped to the request,
+			// available to handlers through logger.FromContext(ctx)
+			requestID := ensureRequestID(ctx)
+			reqLogger := tracing.WithTraceFields(ctx, httpLog.With().Str("request_id", requestID)).Logger()
+			logger.IntoContext(ctx, &reqLogger)
+			
 			// Skip logging for specified paths
 			for _, skipPath := range options.SkipPaths {
 				if strings.HasPrefix(path, skipPath) {
@@ -56,28 +120,26 @@ func EnhancedRequestLogger(options *LoggingOptions) func(fasthttp.RequestHandler
 				}
 			}
 			
-			// Record start time
-			start := time.Now()
-			
-			// Create request ID for tracing
-			requestID := fmt.Sprintf("%016X", start.UnixNano())
-			
 			// Extract request information
 			method := string(ctx.Method())
-			uri := string(ctx.RequestURI())
+			uri := redactURI(string(ctx.RequestURI()), orDefault(options.RedactQueryParams, config.DefaultRedactKeys))
 			clientIP := ctx.RemoteIP().String()
 			
 			// Log request start with basic info
-			logEvent := logger.Debug().
-				Str("request_id", requestID).
+			logEvent := reqLogger.Debug().
 				Str("client_ip", clientIP).
 				Str("method", method).
 				Str("uri", uri)
 			
 			// Log headers if enabled
 			if options.LogHeaders {
+				redactHeaders := orDefault(options.RedactHeaders, config.DefaultRedactHeaders)
 				headers := make(map[string]string)
 				ctx.Request.Header.VisitAll(func(key, value []byte) {
+					if matchAny(redactHeaders, string(key)) {
+						headers[string(key)] = redacted
+						return
+					}
 					headers[string(key)] = string(value)
 				})
 				logEvent.Interface("headers", headers)
@@ -85,7 +147,7 @@ func EnhancedRequestLogger(options *LoggingOptions) func(fasthttp.RequestHandler
 			
 			// Log request body if enabled and present
 			if options.LogRequestBody && ctx.Request.Header.ContentLength() > 0 {
-				body := string(ctx.Request.Body())
+				body := string(redactBody(ctx.Request.Body(), string(ctx.Request.Header.ContentType()), orDefault(options.RedactBodyKeys, config.DefaultRedactKeys)))
 				if len(body) > options.MaxBodyLogSize {
 					body = body[:options.MaxBodyLogSize] + "... (truncated)"
 				}
@@ -108,16 +170,15 @@ func EnhancedRequestLogger(options *LoggingOptions) func(fasthttp.RequestHandler
 			// Determine log level based on status code
 			var completeLogEvent *zerolog.Event
 			if statusCode >= 500 {
-				completeLogEvent = logger.Error()
+				completeLogEvent = reqLogger.Error()
 			} else if statusCode >= 400 {
-				completeLogEvent = logger.Warn()
+				completeLogEvent = reqLogger.Warn()
 			} else {
-				completeLogEvent = logger.Info()
+				completeLogEvent = reqLogger.Info()
 			}
 			
 			// Add common fields
 			completeLogEvent.
-				Str("request_id", requestID).
 				Str("client_ip", clientIP).
 				Str("method", method).
 				Str("path", path).
@@ -132,7 +193,7 @@ func EnhancedRequestLogger(options *LoggingOptions) func(fasthttp.RequestHandler
 			
 			// Log response body if enabled
 			if options.LogResponseBody {
-				body := string(ctx.Response.Body())
+				body := string(redactBody(ctx.Response.Body(), string(ctx.Response.Header.ContentType()), orDefault(options.RedactBodyKeys, config.DefaultRedactKeys)))
 				if len(body) > options.MaxBodyLogSize {
 					body = body[:options.MaxBodyLogSize] + "... (truncated)"
 				}
@@ -184,4 +184,114 @@ func TestEnhancedRequestLogger(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestReloadableRequestLogger(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutput(buffer)
+
+	options := NewReloadableLoggingOptions(nil)
+	handler := ReloadableRequestLogger(options)(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(200)
+	})
+	request := func(path string) {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("http://localhost" + path)
+		handler(ctx)
+	}
+
+	request("/api")
+	if !strings.Contains(buffer.String(), "Request completed") {
+		t.Error("Expected /api to be logged with the default options")
+	}
+
+	// Swapped options apply to the next request without rebuilding the handler
+	buffer.Reset()
+	options.Store(&LoggingOptions{SkipPaths: []string{"/api"}})
+	request("/api")
+	if buffer.Len() != 0 {
+		t.Errorf("Expected /api to be skipped after reload, got: %s", buffer.String())
+	}
+	request("/health")
+	if !strings.Contains(buffer.String(), "Request completed") {
+		t.Error("Expected /health to be logged after reload")
+	}
+}
+
+func TestRequestLoggerScopesContextLogger(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutputFormat(buffer, logger.JSONFormat)
+	defer logger.SetOutputFormat(new(bytes.Buffer), logger.ConsoleFormat)
+
+	handler := EnhancedRequestLogger(&LoggingOptions{SkipPaths: []string{"/health"}})(func(ctx *fasthttp.RequestCtx) {
+		logger.FromContext(ctx).Info().Msg("handler message")
+	})
+
+	for _, path := range []string{"/api", "/health"} {
+		buffer.Reset()
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("http://localhost" + path)
+		handler(ctx)
+
+		for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+			if !strings.Contains(line, `"request_id":"`) {
+				t.Errorf("Expected every line for %s to carry the request ID, got %s", path, line)
+			}
+		}
+		if !strings.Contains(buffer.String(), "handler message") {
+			t.Errorf("Expected the handler to log through the request logger for %s", path)
+		}
+	}
+}
+
+func TestRequestLoggerRedactsSecrets(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutput(buffer)
+
+	handler := EnhancedRequestLogger(&LoggingOptions{
+		LogHeaders:      true,
+		LogRequestBody:  true,
+		LogResponseBody: true,
+		MaxBodyLogSize:  1024,
+		RedactHeaders:   []string{"Authorization", "X-*-Token"},
+	})(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"id":1,"token":"response-secret"}`)
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("http://localhost/login?user=bob&token=query-secret")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.Set("Authorization", "Bearer header-secret")
+	ctx.Request.Header.Set("X-Session-Token", "session-secret")
+	ctx.Request.Header.Set("X-Request-Source", "tests")
+	body := `{"user":"bob","password":"body-secret"}`
+	ctx.Request.SetBodyString(body)
+	ctx.Request.Header.SetContentLength(len(body))
+	handler(ctx)
+
+	output := buffer.String()
+	for _, secret := range []string{"query-secret", "header-secret", "session-secret", "body-secret", "response-secret"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %s to be redacted, got: %s", secret, output)
+		}
+	}
+	for _, kept := range []string{"user=bob", "tests", `\"user\":\"bob\"`, `\"id\":1`} {
+		if !strings.Contains(output, kept) {
+			t.Errorf("Expected %s to be logged, got: %s", kept, output)
+		}
+	}
+
+	buffer.Reset()
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("http://localhost/login")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetContentType("application/x-www-form-urlencoded")
+	form := "user=bob&password=form-secret"
+	ctx.Request.SetBodyString(form)
+	ctx.Request.Header.SetContentLength(len(form))
+	handler(ctx)
+
+	output = buffer.String()
+	if strings.Contains(output, "form-secret") || !strings.Contains(output, "user=bob&password=<redacted>") {
+		t.Errorf("Expected the form password to be redacted, got: %s", output)
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+
+	"k8s-controller/pkg/tracing"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing is a middleware that wraps every request in a server span. The
+// span continues the trace of an incoming W3C traceparent header and is
+// stored on the RequestCtx, where tracing.Start finds it as the parent of
+// spans created by handlers.
+func Tracing(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		method := string(ctx.Method())
+		parent := otel.GetTextMapPropagator().Extract(ctx, headerCarrier{&ctx.Request.Header})
+
+		_, span := tracing.Tracer().Start(parent, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(string(ctx.Path())),
+				semconv.ClientAddress(ctx.RemoteIP().String()),
+				semconv.UserAgentOriginal(string(ctx.UserAgent())),
+			),
+		)
+		defer span.End()
+		tracing.ContextWithSpan(ctx, span)
+
+		next(ctx)
+
+		// Name the span after the route, as the path may contain IDs
+		if route := RouteFromContext(ctx); route != "" {
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		status := ctx.Response.StatusCode()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// headerCarrier adapts fasthttp request headers to a propagation.TextMapCarrier
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (c headerCarrier) Get(key string) string {
+	return string(c.header.Peek(key))
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c.header.Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	var keys []string
+	c.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s-controller/pkg/logger"
+	"k8s-controller/pkg/tracing"
+	"k8s-controller/pkg/tracing/tracingtest"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger.SetOutputFormat(buffer, logger.JSONFormat)
+	defer logger.SetOutputFormat(new(bytes.Buffer), logger.ConsoleFormat)
+
+	exporter, restore := tracingtest.InMemory()
+	defer restore()
+
+	handler := Tracing(RequestID(EnhancedRequestLogger(nil)(func(ctx *fasthttp.RequestCtx) {
+		_, span := tracing.Start(ctx, "handler work")
+		span.End()
+		ctx.SetStatusCode(fasthttp.StatusBadGateway)
+	})))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("http://localhost/api/items")
+	ctx.Request.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler(ctx)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected a server span and a handler span, got %d", len(spans))
+	}
+	child, server := spans[0], spans[1]
+
+	if server.Name != "GET" || server.SpanKind != trace.SpanKindServer {
+		t.Errorf("Expected a GET server span, got %s (%s)", server.Name, server.SpanKind)
+	}
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the server span to continue the incoming trace, got %s", got)
+	}
+	if got := server.Parent.SpanID().String(); got != "00f067aa0ba902b7" || !server.Parent.IsRemote() {
+		t.Errorf("Expected the remote caller as parent, got %s", got)
+	}
+	if server.Status.Code != codes.Error {
+		t.Errorf("Expected a 5xx response to mark the span as failed, got %v", server.Status.Code)
+	}
+	attrs := make(map[string]string)
+	for _, attr := range server.Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["http.request.method"] != "GET" || attrs["url.path"] != "/api/items" || attrs["http.response.status_code"] != "502" {
+		t.Errorf("Unexpected server span attributes: %v", attrs)
+	}
+
+	if child.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Error("Expected the handler span to be a child of the server span")
+	}
+
+	traceID := server.SpanContext.TraceID().String()
+	if !strings.Contains(buffer.String(), `"trace_id":"`+traceID+`"`) {
+		t.Errorf("Expected request log lines to carry the trace ID, got %s", buffer.String())
+	}
+}
+
+func TestTracingRequestIDFollowsTrace(t *testing.T) {
+	exporter, restore := tracingtest.InMemory()
+	defer restore()
+
+	handler := Tracing(RequestID(func(ctx *fasthttp.RequestCtx) {}))
+	ctx := &fasthttp.RequestCtx{}
+	handler(ctx)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	if got := string(ctx.Response.Header.Peek(RequestIDHeader)); got != spans[0].SpanContext.TraceID().String() {
+		t.Errorf("Expected the request ID to be the new trace ID, got %s", got)
+	}
+}
+
+func TestTracingNamesSpanAfterRoute(t *testing.T) {
+	exporter, restore := tracingtest.InMemory()
+	defer restore()
+
+	handler := Tracing(func(ctx *fasthttp.RequestCtx) {
+		SetRoute(ctx, "/api/items/:id")
+	})
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("http://localhost/api/items/42")
+	ctx.Request.Header.SetMethod(fasthttp.MethodDelete)
+	handler(ctx)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	if spans[0].Name != "DELETE /api/items/:id" {
+		t.Errorf("Expected the span to be named after the route, got %s", spans[0].Name)
+	}
+	found := false
+	for _, attr := range spans[0].Attributes {
+		if attr.Key == "http.route" && attr.Value.AsString() == "/api/items/:id" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected an http.route attribute, got %v", spans[0].Attributes)
+	}
+}
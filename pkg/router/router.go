@@ -0,0 +1,283 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s-controller/pkg/middleware"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Router dispatches requests to handlers by method and path pattern.
+// Patterns are made of "/"-separated segments that are matched literally,
+// ":name" segments that match any single segment and a final "*name"
+// segment that matches the rest of the path. Literal segments take
+// precedence over parameters, and parameters over catch-alls.
+type Router struct {
+	routes
+
+	root *node
+
+	// NotFound handles requests that match no route
+	NotFound fasthttp.RequestHandler
+	// MethodNotAllowed handles requests whose path matches a route that
+	// does not accept the method; the Allow header is already set
+	MethodNotAllowed fasthttp.RequestHandler
+}
+
+// routes names the Group embedded in Router, so that the field does not
+// hide the Group method
+type routes = Group
+
+// Group registers routes below a path prefix, wrapped with the group's
+// middleware
+type Group struct {
+	router     *Router
+	prefix     string
+	middleware middleware.Middleware
+}
+
+// New creates an empty router
+func New() *Router {
+	r := &Router{
+		root:             newNode(),
+		NotFound:         notFound,
+		MethodNotAllowed: methodNotAllowed,
+	}
+	r.routes = Group{router: r, middleware: middleware.Chain()}
+	return r
+}
+
+// Group returns a group for routes below prefix. Its middlewares wrap the
+// handlers of the group's routes, after the middlewares of g.
+func (g *Group) Group(prefix string, middlewares ...middleware.Middleware) *Group {
+	return &Group{
+		router:     g.router,
+		prefix:     g.prefix + strings.TrimSuffix(prefix, "/"),
+		middleware: middleware.Chain(g.middleware, middleware.Chain(middlewares...)),
+	}
+}
+
+// Handle registers handler for method and pattern. It panics when the
+// pattern is invalid or the route is already registered, as routes are set
+// up once at startup.
+func (g *Group) Handle(method, pattern string, handler fasthttp.RequestHandler) {
+	pattern = g.prefix + pattern
+	if err := g.router.root.insert(method, pattern, g.middleware(handler)); err != nil {
+		panic(err)
+	}
+}
+
+// GET registers handler for GET requests; HEAD requests are served by GET
+// routes unless a HEAD route exists
+func (g *Group) GET(pattern string, handler fasthttp.RequestHandler) {
+	g.Handle(fasthttp.MethodGet, pattern, handler)
+}
+
+// POST registers handler for POST requests
+func (g *Group) POST(pattern string, handler fasthttp.RequestHandler) {
+	g.Handle(fasthttp.MethodPost, pattern, handler)
+}
+
+// PUT registers handler for PUT requests
+func (g *Group) PUT(pattern string, handler fasthttp.RequestHandler) {
+	g.Handle(fasthttp.MethodPut, pattern, handler)
+}
+
+// PATCH registers handler for PATCH requests
+func (g *Group) PATCH(pattern string, handler fasthttp.RequestHandler) {
+	g.Handle(fasthttp.MethodPatch, pattern, handler)
+}
+
+// DELETE registers handler for DELETE requests
+func (g *Group) DELETE(pattern string, handler fasthttp.RequestHandler) {
+	g.Handle(fasthttp.MethodDelete, pattern, handler)
+}
+
+// Handler returns the request handler dispatching to the registered routes
+func (r *Router) Handler() fasthttp.RequestHandler {
+	return r.serve
+}
+
+func (r *Router) serve(ctx *fasthttp.RequestCtx) {
+	var params []param
+	n := r.root.match(splitPath(string(ctx.Path())), &params)
+	if n == nil {
+		r.NotFound(ctx)
+		return
+	}
+
+	method := string(ctx.Method())
+	handler, ok := n.handlers[method]
+	if !ok && method == fasthttp.MethodHead {
+		handler, ok = n.handlers[fasthttp.MethodGet]
+	}
+	middleware.SetRoute(ctx, n.pattern)
+	if !ok {
+		ctx.Response.Header.Set(fasthttp.HeaderAllow, n.allow())
+		r.MethodNotAllowed(ctx)
+		return
+	}
+
+	if len(params) > 0 {
+		ctx.SetUserValue(paramsKey{}, params)
+	}
+	handler(ctx)
+}
+
+// paramsKey is the user value key under which path parameters are stored
+type paramsKey struct{}
+
+type param struct {
+	name, value string
+}
+
+// Param returns the value of the path parameter name of the matched route,
+// or "" when there is none
+func Param(ctx context.Context, name string) string {
+	params, _ := ctx.Value(paramsKey{}).([]param)
+	for _, p := range params {
+		if p.name == name {
+			return p.value
+		}
+	}
+	return ""
+}
+
+func notFound(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusNotFound)
+	ctx.SetContentType("text/plain")
+	ctx.SetBodyString("Not found")
+}
+
+// methodNotAllowed does not use ctx.Error, which would drop the Allow header
+func methodNotAllowed(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusMethodNotAllowed)
+	ctx.SetContentType("text/plain")
+	ctx.SetBodyString("Method not allowed")
+}
+
+// splitPath returns the segments of path; "/" has none
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// node is a path segment in the route tree
+type node struct {
+	static   map[string]*node
+	param    *node
+	catchAll *node
+	// name is the parameter name of param and catch-all nodes
+	name string
+
+	// pattern and handlers are set on nodes that end a route
+	pattern  string
+	handlers map[string]fasthttp.RequestHandler
+}
+
+func newNode() *node {
+	return &node{static: make(map[string]*node)}
+}
+
+func (n *node) insert(method, pattern string, handler fasthttp.RequestHandler) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("route pattern %q must start with /", pattern)
+	}
+
+	segments := splitPath(pattern)
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if n.param == nil {
+				n.param = newNode()
+				n.param.name = segment[1:]
+			}
+			if n.param.name != segment[1:] {
+				return fmt.Errorf("route %q names parameter %s, another route uses :%s", pattern, segment, n.param.name)
+			}
+			n = n.param
+		case strings.HasPrefix(segment, "*"):
+			if i != len(segments)-1 {
+				return fmt.Errorf("route %q has a catch-all segment before its end", pattern)
+			}
+			if n.catchAll == nil {
+				n.catchAll = newNode()
+				n.catchAll.name = segment[1:]
+			}
+			if n.catchAll.name != segment[1:] {
+				return fmt.Errorf("route %q names catch-all %s, another route uses *%s", pattern, segment, n.catchAll.name)
+			}
+			n = n.catchAll
+		default:
+			child, ok := n.static[segment]
+			if !ok {
+				child = newNode()
+				n.static[segment] = child
+			}
+			n = child
+		}
+	}
+
+	if n.handlers == nil {
+		n.handlers = make(map[string]fasthttp.RequestHandler)
+	}
+	if _, exists := n.handlers[method]; exists {
+		return fmt.Errorf("route %s %s is already registered", method, pattern)
+	}
+	n.pattern = pattern
+	n.handlers[method] = handler
+	return nil
+}
+
+// match returns the node of the route matching segments, appending path
+// parameters to params, or nil when no route matches
+func (n *node) match(segments []string, params *[]param) *node {
+	if len(segments) == 0 {
+		if n.handlers != nil {
+			return n
+		}
+		return nil
+	}
+
+	segment, rest := segments[0], segments[1:]
+	if child, ok := n.static[segment]; ok {
+		if found := child.match(rest, params); found != nil {
+			return found
+		}
+	}
+	if n.param != nil && segment != "" {
+		mark := len(*params)
+		*params = append(*params, param{name: n.param.name, value: segment})
+		if found := n.param.match(rest, params); found != nil {
+			return found
+		}
+		*params = (*params)[:mark]
+	}
+	if n.catchAll != nil && n.catchAll.handlers != nil {
+		*params = append(*params, param{name: n.catchAll.name, value: strings.Join(segments, "/")})
+		return n.catchAll
+	}
+	return nil
+}
+
+// allow lists the methods of the route for the Allow header
+func (n *node) allow() string {
+	methods := make([]string, 0, len(n.handlers)+1)
+	for method := range n.handlers {
+		methods = append(methods, method)
+	}
+	if _, ok := n.handlers[fasthttp.MethodGet]; ok {
+		if _, ok := n.handlers[fasthttp.MethodHead]; !ok {
+			methods = append(methods, fasthttp.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
@@ -0,0 +1,229 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s-controller/pkg/middleware"
+
+	"github.com/valyala/fasthttp"
+)
+
+// reply returns a handler that writes name and the given path parameters
+func reply(name string, params ...string) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		fmt.Fprint(ctx, name)
+		for _, p := range params {
+			fmt.Fprintf(ctx, " %s=%s", p, Param(ctx, p))
+		}
+	}
+}
+
+func serve(handler fasthttp.RequestHandler, method, path string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI("http://localhost" + path)
+	handler(ctx)
+	return ctx
+}
+
+func TestRouterMatching(t *testing.T) {
+	r := New()
+	r.GET("/", reply("root"))
+	r.GET("/items", reply("list"))
+	r.GET("/items/new", reply("new"))
+	r.GET("/items/:id", reply("item", "id"))
+	r.GET("/items/:id/tags/:tag", reply("tag", "id", "tag"))
+	r.GET("/files/*path", reply("file", "path"))
+	r.GET("/files/readme", reply("readme"))
+	handler := r.Handler()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+		route  string
+	}{
+		{name: "root", path: "/", status: 200, body: "root", route: "/"},
+		{name: "static", path: "/items", status: 200, body: "list", route: "/items"},
+		{name: "static before param", path: "/items/new", status: 200, body: "new", route: "/items/new"},
+		{name: "param", path: "/items/42", status: 200, body: "item id=42", route: "/items/:id"},
+		{name: "nested params", path: "/items/42/tags/red", status: 200, body: "tag id=42 tag=red", route: "/items/:id/tags/:tag"},
+		{name: "param backtracks from static", path: "/items/new/tags/red", status: 200, body: "tag id=new tag=red", route: "/items/:id/tags/:tag"},
+		{name: "static before catch-all", path: "/files/readme", status: 200, body: "readme", route: "/files/readme"},
+		{name: "catch-all", path: "/files/docs/guide.md", status: 200, body: "file path=docs/guide.md", route: "/files/*path"},
+		{name: "empty catch-all", path: "/files/", status: 200, body: "file path=", route: "/files/*path"},
+		{name: "empty param", path: "/items//tags/red", status: 404, body: "Not found"},
+		{name: "unknown", path: "/unknown", status: 404, body: "Not found"},
+		{name: "too long", path: "/items/42/tags", status: 404, body: "Not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := serve(handler, fasthttp.MethodGet, tt.path)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, ctx.Response.StatusCode())
+			}
+			if string(ctx.Response.Body()) != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, ctx.Response.Body())
+			}
+			if got := middleware.RouteFromContext(ctx); got != tt.route {
+				t.Errorf("Expected route %q, got %q", tt.route, got)
+			}
+		})
+	}
+}
+
+func TestRouterMethods(t *testing.T) {
+	r := New()
+	r.GET("/items/:id", reply("get"))
+	r.PUT("/items/:id", reply("put"))
+	r.DELETE("/items/:id", reply("delete"))
+	r.POST("/items", reply("post"))
+	r.PATCH("/items/:id", reply("patch"))
+	handler := r.Handler()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{name: "GET", method: "GET", path: "/items/1", status: 200, body: "get"},
+		{name: "PUT", method: "PUT", path: "/items/1", status: 200, body: "put"},
+		{name: "PATCH", method: "PATCH", path: "/items/1", status: 200, body: "patch"},
+		{name: "DELETE", method: "DELETE", path: "/items/1", status: 200, body: "delete"},
+		{name: "POST", method: "POST", path: "/items", status: 200, body: "post"},
+		{name: "HEAD falls back to GET", method: "HEAD", path: "/items/1", status: 200, body: "get"},
+		{name: "not allowed", method: "POST", path: "/items/1", status: 405, body: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := serve(handler, tt.method, tt.path)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, ctx.Response.StatusCode())
+			}
+			if string(ctx.Response.Body()) != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, ctx.Response.Body())
+			}
+		})
+	}
+
+	ctx := serve(handler, fasthttp.MethodPost, "/items/1")
+	if got := string(ctx.Response.Header.Peek(fasthttp.HeaderAllow)); got != "DELETE, GET, HEAD, PATCH, PUT" {
+		t.Errorf("Expected Allow header to list the route's methods, got %q", got)
+	}
+	if got := middleware.RouteFromContext(ctx); got != "/items/:id" {
+		t.Errorf("Expected a 405 response to record its route, got %q", got)
+	}
+}
+
+func TestRouterGroups(t *testing.T) {
+	var calls []string
+	record := func(name string) middleware.Middleware {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return func(ctx *fasthttp.RequestCtx) {
+				calls = append(calls, name)
+				next(ctx)
+			}
+		}
+	}
+
+	r := New()
+	r.GET("/health", reply("health"))
+	api := r.Group("/api/", record("api"))
+	api.GET("/items", reply("items"))
+	admin := api.Group("/admin", record("auth"), record("audit"))
+	admin.DELETE("/items/:id", reply("purge", "id"))
+	handler := r.Handler()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		calls  []string
+	}{
+		{name: "outside groups", method: "GET", path: "/health", body: "health"},
+		{name: "group", method: "GET", path: "/api/items", body: "items", calls: []string{"api"}},
+		{name: "nested group", method: "DELETE", path: "/api/admin/items/7", body: "purge id=7", calls: []string{"api", "auth", "audit"}},
+		{name: "unmatched path skips middleware", method: "GET", path: "/api/unknown", body: "Not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			ctx := serve(handler, tt.method, tt.path)
+			if string(ctx.Response.Body()) != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, ctx.Response.Body())
+			}
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("Expected middleware calls %v, got %v", tt.calls, calls)
+			}
+		})
+	}
+}
+
+func TestRouterCustomHandlers(t *testing.T) {
+	r := New()
+	r.GET("/items", reply("items"))
+	r.NotFound = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString("custom not found")
+	}
+	r.MethodNotAllowed = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusMethodNotAllowed)
+		ctx.SetBodyString("custom not allowed " + string(ctx.Response.Header.Peek(fasthttp.HeaderAllow)))
+	}
+	handler := r.Handler()
+
+	if ctx := serve(handler, fasthttp.MethodGet, "/missing"); string(ctx.Response.Body()) != "custom not found" {
+		t.Errorf("Expected the custom NotFound handler, got %q", ctx.Response.Body())
+	}
+	if ctx := serve(handler, fasthttp.MethodPut, "/items"); string(ctx.Response.Body()) != "custom not allowed GET, HEAD" {
+		t.Errorf("Expected the custom MethodNotAllowed handler, got %q", ctx.Response.Body())
+	}
+}
+
+func TestRouterInvalidRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *Router)
+	}{
+		{name: "duplicate", register: func(r *Router) {
+			r.GET("/items", reply("a"))
+			r.GET("/items", reply("b"))
+		}},
+		{name: "no leading slash", register: func(r *Router) {
+			r.GET("items", reply("a"))
+		}},
+		{name: "conflicting parameter names", register: func(r *Router) {
+			r.GET("/items/:id", reply("a"))
+			r.PUT("/items/:name", reply("b"))
+		}},
+		{name: "catch-all before end", register: func(r *Router) {
+			r.GET("/files/*path/meta", reply("a"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected registering the route to panic")
+				}
+			}()
+			tt.register(New())
+		})
+	}
+}
+
+func TestParamWithoutRoute(t *testing.T) {
+	if got := Param(&fasthttp.RequestCtx{}, "id"); got != "" {
+		t.Errorf("Expected no parameter outside a route, got %q", got)
+	}
+}
@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"k8s-controller/pkg/logger"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of every span created by the application
+const instrumentationName = "k8s-controller"
+
+// OTLP protocols supported by Setup
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// log reports errors of the OpenTelemetry SDK, such as failed exports
+var log = logger.Component("tracing")
+
+func init() {
+	// Trace context is propagated even while no exporter is set up, so
+	// incoming trace IDs still reach the logs
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Warn().Err(err).Msg("OpenTelemetry error")
+	}))
+}
+
+// Options configures the OTLP exporter installed by Setup
+type Options struct {
+	// Endpoint is the collector's host:port; empty uses
+	// OTEL_EXPORTER_OTLP_ENDPOINT or the exporter's default
+	Endpoint string
+	// Protocol is ProtocolGRPC or ProtocolHTTP
+	Protocol string
+	// Insecure disables TLS
+	Insecure bool
+	// Headers are sent with every export request, e.g. for authentication
+	Headers map[string]string
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+	// SampleRatio is the fraction of new traces that are sampled; traces
+	// started by a caller follow the caller's decision
+	SampleRatio float64
+}
+
+// Setup installs a global tracer provider that exports spans over OTLP in
+// batches. The returned function flushes pending spans and shuts the
+// provider down.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	exporter, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, opts Options) (*otlptrace.Exporter, error) {
+	switch opts.Protocol {
+	case ProtocolGRPC:
+		var grpcOpts []otlptracegrpc.Option
+		if opts.Endpoint != "" {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+		}
+		if len(opts.Headers) > 0 {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithHeaders(opts.Headers))
+		}
+		return otlptracegrpc.New(ctx, grpcOpts...)
+	case ProtocolHTTP:
+		var httpOpts []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+		if len(opts.Headers) > 0 {
+			httpOpts = append(httpOpts, otlptracehttp.WithHeaders(opts.Headers))
+		}
+		return otlptracehttp.New(ctx, httpOpts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", opts.Protocol)
+	}
+}
+
+// Tracer returns the application tracer of the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// spanKey is the user value key under which ContextWithSpan stores a span
+type spanKey struct{}
+
+// ContextWithSpan returns a context carrying span. A fasthttp.RequestCtx,
+// or any context with a SetUserValue method, stores span as a user value and
+// is returned as is, so handlers further down the chain find it.
+func ContextWithSpan(ctx context.Context, span trace.Span) context.Context {
+	if uv, ok := ctx.(interface{ SetUserValue(key, value any) }); ok {
+		uv.SetUserValue(spanKey{}, span)
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, span)
+}
+
+// SpanFromContext returns the current span of ctx, including a span stored
+// on a fasthttp.RequestCtx by ContextWithSpan
+func SpanFromContext(ctx context.Context) trace.Span {
+	span := trace.SpanFromContext(ctx)
+	if ctx == nil || span.SpanContext().IsValid() {
+		return span
+	}
+	if stored, ok := ctx.Value(spanKey{}).(trace.Span); ok {
+		return stored
+	}
+	return span
+}
+
+// Start starts a span as a child of the current span of ctx
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(trace.ContextWithSpan(ctx, SpanFromContext(ctx)), name, opts...)
+}
+
+// WithTraceFields adds the trace_id and span_id of the current span of ctx
+// to a logger context, so log lines can be joined with their trace
+func WithTraceFields(ctx context.Context, zc zerolog.Context) zerolog.Context {
+	sc := SpanFromContext(ctx).SpanContext()
+	if !sc.IsValid() {
+		return zc
+	}
+	return zc.Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String())
+}
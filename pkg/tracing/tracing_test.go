@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"k8s-controller/pkg/tracing/tracingtest"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// userValueContext mimics a fasthttp.RequestCtx
+type userValueContext struct {
+	context.Context
+	values map[any]any
+}
+
+func (c *userValueContext) SetUserValue(key, value any) {
+	c.values[key] = value
+}
+
+func (c *userValueContext) Value(key any) any {
+	if v, ok := c.values[key]; ok {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+func TestSpanOnUserValueContext(t *testing.T) {
+	exporter, restore := tracingtest.InMemory()
+	defer restore()
+
+	ctx := &userValueContext{Context: context.Background(), values: make(map[any]any)}
+	_, parent := Tracer().Start(context.Background(), "request")
+	if got := ContextWithSpan(ctx, parent); got != ctx {
+		t.Error("Expected the span to be stored on the context itself")
+	}
+
+	if SpanFromContext(ctx) != parent {
+		t.Error("Expected SpanFromContext to find the stored span")
+	}
+	childCtx, child := Start(ctx, "child")
+	if SpanFromContext(childCtx) != child {
+		t.Error("Expected the returned context to carry the child span")
+	}
+	child.End()
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Errorf("Expected the child to be parented to the stored span, got %+v", spans)
+	}
+}
+
+func TestWithTraceFields(t *testing.T) {
+	_, restore := tracingtest.InMemory()
+	defer restore()
+
+	buf := new(bytes.Buffer)
+	base := zerolog.New(buf)
+
+	l := WithTraceFields(context.Background(), base.With()).Logger()
+	l.Info().Msg("no span")
+	if strings.Contains(buf.String(), "trace_id") {
+		t.Errorf("Expected no trace fields without a span, got %s", buf.String())
+	}
+
+	buf.Reset()
+	ctx, span := Start(context.Background(), "work")
+	defer span.End()
+	l = WithTraceFields(ctx, base.With()).Logger()
+	l.Info().Msg("in span")
+
+	sc := trace.SpanContextFromContext(ctx)
+	for _, field := range []string{`"trace_id":"` + sc.TraceID().String(), `"span_id":"` + sc.SpanID().String()} {
+		if !strings.Contains(buf.String(), field) {
+			t.Errorf("Expected %s in %s", field, buf.String())
+		}
+	}
+}
+
+func TestSetupRejectsUnknownProtocol(t *testing.T) {
+	if _, err := Setup(context.Background(), Options{Protocol: "zipkin"}); err == nil {
+		t.Error("Expected an error for an unknown protocol")
+	}
+}
+
+func TestSetupExportsOverHTTP(t *testing.T) {
+	var exports atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("X-Api-Key") != "secret" {
+			t.Errorf("Unexpected export request %s with headers %v", r.URL.Path, r.Header)
+		}
+		exports.Add(1)
+	}))
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	shutdown, err := Setup(context.Background(), Options{
+		Endpoint:    strings.TrimPrefix(collector.URL, "http://"),
+		Protocol:    ProtocolHTTP,
+		Insecure:    true,
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+		ServiceName: "test",
+		SampleRatio: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+	_, span := Start(context.Background(), "exported")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to flush spans: %v", err)
+	}
+	if exports.Load() == 0 {
+		t.Error("Expected spans to be exported to the collector")
+	}
+}